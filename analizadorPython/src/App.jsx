@@ -2,23 +2,34 @@ import { useState } from "react";
 import TablaTokens from "./shared/component/TablaTokens";
 import BotonComponent from "./shared/component/ButtonComponent";
 import SpanComponent from "./shared/component/SpanComponent";
+import TrazaParser from "./shared/component/TrazaParser";
+import Derivacion from "./shared/component/Derivacion";
+import ArbolSintactico from "./shared/component/ArbolSintactico";
 import "./App.css";
 
 function App() {
   const [code, setCode] = useState("");
   const [result, setResult] = useState(null);
   const [loading, setLoading] = useState(false);
+  const [derivacion, setDerivacion] = useState(false);
+  const [tipoParser, setTipoParser] = useState("");
+  const [codigoAnalizado, setCodigoAnalizado] = useState("");
 
   const analizar = async () => {
     setLoading(true);
     setResult(null);
     try {
-      const res = await fetch("http://localhost:8080/analyze", {
+      const params = new URLSearchParams();
+      if (derivacion) params.set("derivation", "true");
+      if (tipoParser) params.set("parser", tipoParser);
+      const url = "http://localhost:8080/analyze?" + params.toString();
+      const res = await fetch(url, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({ code }),
       });
       const data = await res.json();
+      setCodigoAnalizado(code);
       setResult(data);
     } catch{
       setResult({ error: "Error de conexión con el backend" });
@@ -42,6 +53,23 @@ function App() {
       <br />
       <BotonComponent onClick={analizar} children={'analizar'}/>
       <BotonComponent onClick={() => setCode("")} children='Limpiar'/>
+      <label style={{ marginLeft: 8 }}>
+        <input
+          type="checkbox"
+          checked={derivacion}
+          onChange={e => setDerivacion(e.target.checked)}
+        />
+        Derivación por la izquierda
+      </label>
+      <select
+        value={tipoParser}
+        onChange={e => setTipoParser(e.target.value)}
+        style={{ marginLeft: 8 }}
+      >
+        <option value="">Descenso recursivo</option>
+        <option value="ll1">LL(1) dirigido por tabla</option>
+        <option value="lalr">LALR(1) desplazamiento-reducción</option>
+      </select>
       {loading && <SpanComponent style={{ color: "blue" }} children={'Analizando...'}/>}
       {result && (
         <div style={{ marginTop: 24 }}>
@@ -67,9 +95,35 @@ function App() {
               <h3>Análisis Sintáctico</h3>
               <SpanComponent>
                 {result.syntax_analysis.errors && result.syntax_analysis.errors.length > 0
-                  ? result.syntax_analysis.errors.join(", ")
+                  ? result.syntax_analysis.errors
+                      .map((e) => `Línea ${e.span.line}, columna ${e.span.column}: ${e.message}`)
+                      .join(", ")
                   : "Sin errores de sintaxis"}
               </SpanComponent>
+              {result.syntax_analysis.unparsed && (
+                <pre>{result.syntax_analysis.unparsed}</pre>
+              )}
+              {result.syntax_analysis.ast && (
+                <>
+                  <h4>Árbol sintáctico</h4>
+                  <ArbolSintactico ast={result.syntax_analysis.ast} code={codigoAnalizado} />
+                </>
+              )}
+              {result.syntax_analysis.derivation && (
+                <>
+                  <h4>Derivación por la izquierda</h4>
+                  <Derivacion
+                    inicial={result.syntax_analysis.parse_tree.symbol}
+                    pasos={result.syntax_analysis.derivation}
+                  />
+                </>
+              )}
+              {result.syntax_analysis.trace && (
+                <>
+                  <h4>Traza del parser</h4>
+                  <TrazaParser trace={result.syntax_analysis.trace} />
+                </>
+              )}
             </>
           )}
           {result.semantic_analysis && (
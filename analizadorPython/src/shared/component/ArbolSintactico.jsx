@@ -0,0 +1,92 @@
+import React, { useState } from "react";
+
+// Convierte una línea y columna del backend, que empiezan en 1 y cuentan
+// bytes UTF-8, en un índice del string del código.
+function indice(lineas, linea, columna) {
+  let inicio = 0;
+  for (let i = 0; i < linea - 1 && i < lineas.length; i++) {
+    inicio += lineas[i].length + 1;
+  }
+  const texto = lineas[linea - 1] || "";
+  const bytes = new TextEncoder().encode(texto).slice(0, columna - 1);
+  return inicio + new TextDecoder().decode(bytes).length;
+}
+
+// Nodo del árbol: al hacer clic se selecciona y con el triángulo se
+// pliegan sus hijos. La anotación de tipo va como un hijo más.
+function Nodo({ nodo, etiqueta, seleccionado, onSelect }) {
+  const [abierto, setAbierto] = useState(true);
+  const hijos = (nodo.children || []).map((hijo) => ({ nodo: hijo }));
+  if (nodo.annotation) hijos.push({ nodo: nodo.annotation, etiqueta: "anotación" });
+
+  return (
+    <li>
+      <span
+        onClick={() => setAbierto(!abierto)}
+        style={{ cursor: "pointer", display: "inline-block", width: 14 }}
+      >
+        {hijos.length > 0 ? (abierto ? "▾" : "▸") : ""}
+      </span>
+      <span
+        onClick={() => onSelect(nodo)}
+        style={{
+          cursor: "pointer",
+          background: seleccionado === nodo ? "#ffe58f" : "transparent",
+        }}
+      >
+        {etiqueta && <i>{etiqueta}: </i>}
+        {nodo.type}
+        {nodo.value && <b> {nodo.value}</b>}
+        <span style={{ color: "gray" }}>
+          {" "}[{nodo.span.line}:{nodo.span.column}–{nodo.span.end_line}:{nodo.span.end_column}]
+        </span>
+      </span>
+      {abierto && hijos.length > 0 && (
+        <ul style={{ listStyle: "none", paddingLeft: 16, margin: 0 }}>
+          {hijos.map((hijo, i) => (
+            <Nodo
+              key={i}
+              nodo={hijo.nodo}
+              etiqueta={hijo.etiqueta}
+              seleccionado={seleccionado}
+              onSelect={onSelect}
+            />
+          ))}
+        </ul>
+      )}
+    </li>
+  );
+}
+
+// Muestra el árbol sintáctico junto al código analizado y resalta en el
+// código la región del nodo seleccionado.
+function ArbolSintactico({ ast, code }) {
+  const [seleccionado, setSeleccionado] = useState(null);
+  if (!ast) return <div>No hay árbol</div>;
+
+  let contenido = code;
+  if (seleccionado) {
+    const lineas = code.split("\n");
+    const { span } = seleccionado;
+    const desde = indice(lineas, span.line, span.column);
+    const hasta = indice(lineas, span.end_line, span.end_column);
+    contenido = (
+      <>
+        {code.slice(0, desde)}
+        <mark>{code.slice(desde, hasta)}</mark>
+        {code.slice(hasta)}
+      </>
+    );
+  }
+
+  return (
+    <div style={{ display: "flex", gap: 16, marginTop: 8, alignItems: "flex-start" }}>
+      <ul style={{ listStyle: "none", paddingLeft: 0, margin: 0, fontFamily: "monospace", flex: 1 }}>
+        <Nodo nodo={ast} seleccionado={seleccionado} onSelect={setSeleccionado} />
+      </ul>
+      <pre style={{ flex: 1, margin: 0, border: "1px solid #ccc", padding: 8 }}>{contenido}</pre>
+    </div>
+  );
+}
+
+export default ArbolSintactico;
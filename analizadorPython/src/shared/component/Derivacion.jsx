@@ -0,0 +1,61 @@
+import React, { useMemo, useState } from "react";
+
+// Rehace la forma sentencial después de los primeros n pasos de la
+// derivación: cada paso reemplaza el símbolo en su posición por el cuerpo
+// de la producción.
+function formaSentencial(inicial, pasos, n) {
+  const forma = [inicial];
+  for (let i = 0; i < n; i++) {
+    forma.splice(pasos[i].position, 1, ...pasos[i].body);
+  }
+  return forma;
+}
+
+// Muestra la derivación por la izquierda paso a paso: la forma sentencial
+// con el símbolo que se expande resaltado y la producción aplicada.
+function Derivacion({ inicial, pasos }) {
+  const [paso, setPaso] = useState(0);
+  const total = pasos ? pasos.length : 0;
+  const forma = useMemo(() => formaSentencial(inicial, pasos, paso), [inicial, pasos, paso]);
+
+  if (total === 0) return <div>No hay pasos</div>;
+
+  const actual = pasos[paso];
+  const produccion = `${forma[actual.position]} → ${actual.body.length > 0 ? actual.body.join(" ") : "ε"}`;
+
+  return (
+    <div style={{ marginTop: 8 }}>
+      <button onClick={() => setPaso(Math.max(paso - 1, 0))}>Anterior</button>
+      <button onClick={() => setPaso(Math.min(paso + 1, total - 1))}>Siguiente</button>
+      <input
+        type="range"
+        min={0}
+        max={total - 1}
+        value={paso}
+        onChange={e => setPaso(Number(e.target.value))}
+        style={{ marginLeft: 8, verticalAlign: "middle" }}
+      />
+      <span style={{ marginLeft: 8 }}>Paso {paso + 1} de {total}</span>
+      <table border="1" cellPadding="4" style={{ marginTop: 8, fontFamily: "monospace" }}>
+        <tbody>
+          <tr>
+            <th>Forma</th>
+            <td>
+              {forma.map((simbolo, i) => (
+                <span key={i} style={{ background: i === actual.position ? "#ffe58f" : "transparent" }}>
+                  {simbolo}{" "}
+                </span>
+              ))}
+            </td>
+          </tr>
+          <tr>
+            <th>Producción</th>
+            <td>{produccion}</td>
+          </tr>
+        </tbody>
+      </table>
+    </div>
+  );
+}
+
+export default Derivacion;
@@ -0,0 +1,76 @@
+import React, { useEffect, useMemo, useState } from "react";
+
+// Rehace la pila del paso n: cada paso quita los últimos pop símbolos de la
+// pila anterior y agrega los de push.
+function pilaDelPaso(pasos, n) {
+  const pila = [];
+  for (let i = 0; i <= n; i++) {
+    pila.splice(pila.length - pasos[i].pop, pasos[i].pop, ...(pasos[i].push || []));
+  }
+  return pila;
+}
+
+// Muestra la traza de un parser dirigido por tabla paso a paso: la pila, la
+// entrada que falta y la acción. Con "Reproducir" avanza solo.
+function TrazaParser({ trace }) {
+  const [paso, setPaso] = useState(0);
+  const [reproduciendo, setReproduciendo] = useState(false);
+  const total = trace && trace.steps ? trace.steps.length : 0;
+  const pila = useMemo(
+    () => (total > 0 ? pilaDelPaso(trace.steps, Math.min(paso, total - 1)) : []),
+    [trace, paso, total]
+  );
+
+  useEffect(() => {
+    if (!reproduciendo) return;
+    if (paso >= total - 1) {
+      setReproduciendo(false);
+      return;
+    }
+    const id = setTimeout(() => setPaso(paso + 1), 400);
+    return () => clearTimeout(id);
+  }, [reproduciendo, paso, total]);
+
+  if (total === 0) return <div>No hay pasos</div>;
+
+  const actual = trace.steps[Math.min(paso, total - 1)];
+  const entrada = trace.input.slice(actual.position).join(" ");
+  const color = actual.kind === "error" ? "red" : actual.kind === "accept" ? "green" : "inherit";
+
+  return (
+    <div style={{ marginTop: 8 }}>
+      <button onClick={() => setPaso(Math.max(paso - 1, 0))}>Anterior</button>
+      <button onClick={() => setReproduciendo(!reproduciendo)}>
+        {reproduciendo ? "Pausar" : "Reproducir"}
+      </button>
+      <button onClick={() => setPaso(Math.min(paso + 1, total - 1))}>Siguiente</button>
+      <input
+        type="range"
+        min={0}
+        max={total - 1}
+        value={paso}
+        onChange={e => setPaso(Number(e.target.value))}
+        style={{ marginLeft: 8, verticalAlign: "middle" }}
+      />
+      <span style={{ marginLeft: 8 }}>Paso {paso + 1} de {total}</span>
+      <table border="1" cellPadding="4" style={{ marginTop: 8, fontFamily: "monospace" }}>
+        <tbody>
+          <tr>
+            <th>Pila</th>
+            <td>{pila.join(" ")}</td>
+          </tr>
+          <tr>
+            <th>Entrada</th>
+            <td>{entrada}</td>
+          </tr>
+          <tr>
+            <th>Acción</th>
+            <td style={{ color }}>{actual.action}</td>
+          </tr>
+        </tbody>
+      </table>
+    </div>
+  );
+}
+
+export default TrazaParser;
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"examencorte2/src/gramatica"
 	"examencorte2/src/lexer"
 	"examencorte2/src/parser"
 	"examencorte2/src/semantico"
@@ -20,6 +23,9 @@ type AnalysisResponse struct {
 	SemanticAnalysis semantico.SemanticResult `json:"semantic_analysis"`
 	Success         bool                    `json:"success"`
 	Error           string                  `json:"error,omitempty"`
+	
+	// Árbol sintáctico en el formato pedido con ?format=
+	Tree            string                  `json:"tree,omitempty"`
 }
 
 func enableCORS(w http.ResponseWriter) {
@@ -41,6 +47,12 @@ func analyzeCode(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    query := r.URL.Query()
+    if err := validateQuery(query); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
     var req AnalysisRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
         http.Error(w, "Invalid JSON", http.StatusBadRequest)
@@ -51,16 +63,34 @@ func analyzeCode(w http.ResponseWriter, r *http.Request) {
     lexicalResult := lexer.Analyze(req.Code)
 
     // Sintáctico: Verifica que los tokens sigan una estructura gramática válida.
-    syntaxResult := parser.Analyze(lexicalResult.Tokens)
+    // Con ?derivation=true también registra el árbol de derivación, y con
+    // ?parser=ll1 o ?parser=lalr usa el parser dirigido por la tabla LL(1)
+    // o por las tablas LALR(1), que arman el árbol de derivación siempre.
+    derivation, _ := strconv.ParseBool(query.Get("derivation"))
+    var syntaxResult parser.SyntaxResult
+    if query.Get("parser") == "ll1" {
+        syntaxResult = parser.AnalyzeLL1(lexicalResult.Tokens)
+    } else if query.Get("parser") == "lalr" {
+        syntaxResult = parser.AnalyzeLALR(lexicalResult.Tokens)
+    } else if derivation {
+        syntaxResult = parser.AnalyzeWithDerivation(lexicalResult.Tokens)
+    } else {
+        syntaxResult = parser.Analyze(lexicalResult.Tokens)
+    }
+    if !derivation {
+        syntaxResult.ParseTree = nil
+        syntaxResult.Derivation = nil
+    }
 
     // Semántico: Verifica el significado: tipos correctos, operaciones válidas, etc.
-    semanticResult := semantico.Analyze(lexicalResult.Tokens, syntaxResult.AST)
+    semanticResult := semantico.Analyze(lexicalResult.Tokens, syntaxResult.Module)
 
     response := AnalysisResponse{
         LexicalAnalysis:  lexicalResult,
         SyntaxAnalysis:   syntaxResult,
         SemanticAnalysis: semanticResult,
         Success:         len(syntaxResult.Errors) == 0 && len(semanticResult.Errors) == 0,
+        Tree:            treeOutput(query, syntaxResult),
     }
 
     if len(syntaxResult.Errors) > 0 {
@@ -73,8 +103,88 @@ func analyzeCode(w http.ResponseWriter, r *http.Request) {
     json.NewEncoder(w).Encode(response)
 }
 
+// validateQuery revisa los parámetros de la consulta antes de analizar:
+// format=dump imprime el árbol como ast.dump de Python, con indent=<n> y
+// attributes=true opcionales; format=dot y format=mermaid lo exportan como
+// grafo de Graphviz o diagrama de Mermaid, derivation=true agrega el
+// árbol de derivación y parser=ll1 o parser=lalr elige un parser dirigido
+// por tabla.
+func validateQuery(query url.Values) error {
+	switch format := query.Get("format"); format {
+	case "", "dump", "dot", "mermaid":
+	default:
+		return fmt.Errorf("Formato desconocido: %q", format)
+	}
+	switch name := query.Get("parser"); name {
+	case "", "ll1", "lalr":
+	default:
+		return fmt.Errorf("Parser desconocido: %q", name)
+	}
+	if indent := query.Get("indent"); indent != "" {
+		if n, err := strconv.Atoi(indent); err != nil || n < 0 {
+			return fmt.Errorf("Valor de indent inválido: %q", indent)
+		}
+	}
+	for _, name := range []string{"attributes", "derivation"} {
+		if value := query.Get(name); value != "" {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("Valor de %s inválido: %q", name, value)
+			}
+		}
+	}
+	return nil
+}
+
+func treeOutput(query url.Values, syntaxResult parser.SyntaxResult) string {
+	switch query.Get("format") {
+	case "dump":
+		options := parser.DumpOptions{}
+		if value := query.Get("indent"); value != "" {
+			indent, _ := strconv.Atoi(value)
+			options.Indent = &indent
+		}
+		options.Attributes, _ = strconv.ParseBool(query.Get("attributes"))
+		return parser.Dump(syntaxResult.Module, options)
+	case "dot":
+		return parser.ToDOT(syntaxResult.AST)
+	case "mermaid":
+		return parser.ToMermaid(syntaxResult.AST)
+	}
+	return ""
+}
+
+// grammarReport devuelve la gramática EBNF del subconjunto reconocido con
+// sus conjuntos FIRST y FOLLOW y los conflictos LL(1) y LALR(1); con
+// ?format=text lo devuelve como texto para leerlo en una terminal.
+func grammarReport(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	
+	report := gramatica.NewReport(gramatica.Python())
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, report.Text())
+	default:
+		http.Error(w, fmt.Sprintf("Formato desconocido: %q", format), http.StatusBadRequest)
+	}
+}
+
 func main() {
 	http.HandleFunc("/analyze", analyzeCode)
+	http.HandleFunc("/grammar", grammarReport)
 	
 	fmt.Println("Servidor iniciado en puerto 8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
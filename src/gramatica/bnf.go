@@ -0,0 +1,99 @@
+package gramatica
+
+import "fmt"
+
+// expander traduce las reglas EBNF a producciones BNF:
+//
+//	[ x ]  →  A → x | ε
+//	{ x }  →  A → x A | ε
+//	( x )  →  A → x
+//
+// donde A es un no terminal auxiliar nuevo.
+type expander struct {
+	g      *Grammar
+	rule   string
+	count  int
+	bodies map[string][][]string
+}
+
+func (g *Grammar) expand() {
+	e := &expander{g: g, bodies: map[string][][]string{}}
+	g.auxiliary = map[string]auxiliary{}
+	for _, rule := range g.Rules {
+		e.rule, e.count = rule.Name, 0
+		g.NonTerminals = append(g.NonTerminals, rule.Name)
+		e.bodies[rule.Name] = e.alternatives(rule.Body)
+	}
+	
+	terminals := map[string]bool{}
+	for _, head := range g.NonTerminals {
+		for _, body := range e.bodies[head] {
+			rule, _ := g.Origin(head)
+			g.Productions = append(g.Productions, Production{Head: head, Body: body, Rule: rule})
+			for _, symbol := range body {
+				if _, ok := e.bodies[symbol]; !ok && !terminals[symbol] {
+					terminals[symbol] = true
+					g.Terminals = append(g.Terminals, symbol)
+				}
+			}
+		}
+	}
+}
+
+// auxiliary crea un no terminal nuevo para la construcción expr. El nombre
+// se reserva antes de traducir el cuerpo para que los auxiliares queden
+// ordenados como aparecen en la regla.
+func (e *expander) auxiliary(expr Expr, bodies func(name string) [][]string) string {
+	e.count++
+	name := fmt.Sprintf("%s_%d", e.rule, e.count)
+	e.g.auxiliary[name] = auxiliary{rule: e.rule, expr: expr}
+	e.g.NonTerminals = append(e.g.NonTerminals, name)
+	e.bodies[name] = bodies(name)
+	return name
+}
+
+func (e *expander) alternatives(expr Expr) [][]string {
+	if choice, ok := expr.(Choice); ok {
+		var bodies [][]string
+		for _, alternative := range choice.Alternatives {
+			bodies = append(bodies, e.sequence(alternative))
+		}
+		return bodies
+	}
+	return [][]string{e.sequence(expr)}
+}
+
+func (e *expander) sequence(expr Expr) []string {
+	if sequence, ok := expr.(Sequence); ok {
+		var symbols []string
+		for _, item := range sequence.Items {
+			symbols = append(symbols, e.symbol(item))
+		}
+		return symbols
+	}
+	return []string{e.symbol(expr)}
+}
+
+func (e *expander) symbol(expr Expr) string {
+	switch expr := expr.(type) {
+	case Terminal:
+		return expr.Name
+	case NonTerminal:
+		return expr.Name
+	case Optional:
+		return e.auxiliary(expr, func(string) [][]string {
+			return append(e.alternatives(expr.Body), []string{})
+		})
+	case Repetition:
+		return e.auxiliary(expr, func(name string) [][]string {
+			bodies := e.alternatives(expr.Body)
+			for i := range bodies {
+				bodies[i] = append(bodies[i], name)
+			}
+			return append(bodies, []string{})
+		})
+	}
+	return e.auxiliary(expr, func(string) [][]string {
+		return e.alternatives(expr)
+	})
+}
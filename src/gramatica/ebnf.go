@@ -0,0 +1,348 @@
+package gramatica
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Expr es una expresión del lado derecho de una regla EBNF.
+type Expr interface {
+	String() string
+}
+
+// Terminal es una clase de token (NAME, NUMBER...) o una palabra o símbolo,
+// que se nombra entre comillas simples como en el árbol de derivación.
+type Terminal struct{ Name string }
+
+type NonTerminal struct{ Name string }
+
+type Sequence struct{ Items []Expr }
+
+type Choice struct{ Alternatives []Expr }
+
+// Optional es [ Body ].
+type Optional struct{ Body Expr }
+
+// Repetition es { Body }: cero o más veces.
+type Repetition struct{ Body Expr }
+
+func (t Terminal) String() string    { return t.Name }
+func (n NonTerminal) String() string { return n.Name }
+func (o Optional) String() string    { return "[ " + o.Body.String() + " ]" }
+func (r Repetition) String() string  { return "{ " + r.Body.String() + " }" }
+
+func (s Sequence) String() string {
+	items := make([]string, len(s.Items))
+	for i, item := range s.Items {
+		if _, ok := item.(Choice); ok {
+			items[i] = "( " + item.String() + " )"
+		} else {
+			items[i] = item.String()
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+func (c Choice) String() string {
+	alternatives := make([]string, len(c.Alternatives))
+	for i, alternative := range c.Alternatives {
+		alternatives[i] = alternative.String()
+	}
+	return strings.Join(alternatives, " | ")
+}
+
+// Parse lee una gramática EBNF y la prepara para el análisis LL(1). La
+// primera regla es el símbolo inicial.
+func Parse(source string) (*Grammar, error) {
+	tokens, err := scanEBNF(source)
+	if err != nil {
+		return nil, err
+	}
+	
+	r := &ebnfReader{tokens: tokens}
+	g := &Grammar{Source: source, rules: map[string]*Rule{}}
+	for r.peek().kind != ebnfEOF {
+		rule, err := r.rule()
+		if err != nil {
+			return nil, err
+		}
+		if previous, ok := g.rules[rule.Name]; ok {
+			return nil, fmt.Errorf("línea %d: la regla %s ya se definió en la línea %d", rule.Line, rule.Name, previous.Line)
+		}
+		g.rules[rule.Name] = rule
+		g.Rules = append(g.Rules, rule)
+	}
+	if len(g.Rules) == 0 {
+		return nil, fmt.Errorf("la gramática no tiene reglas")
+	}
+	g.Start = g.Rules[0].Name
+	
+	if err := g.checkReferences(); err != nil {
+		return nil, err
+	}
+	g.expand()
+	g.computeSets()
+	g.lalr = sync.OnceValue(func() *LALRTable { return newLALR(g) })
+	return g, nil
+}
+
+// checkReferences verifica que todo no terminal usado tenga su regla.
+func (g *Grammar) checkReferences() error {
+	var check func(rule *Rule, e Expr) error
+	check = func(rule *Rule, e Expr) error {
+		switch e := e.(type) {
+		case NonTerminal:
+			if _, ok := g.rules[e.Name]; !ok {
+				return fmt.Errorf("línea %d: la regla %s usa %s, que no está definida", rule.Line, rule.Name, e.Name)
+			}
+		case Sequence:
+			for _, item := range e.Items {
+				if err := check(rule, item); err != nil {
+					return err
+				}
+			}
+		case Choice:
+			for _, alternative := range e.Alternatives {
+				if err := check(rule, alternative); err != nil {
+					return err
+				}
+			}
+		case Optional:
+			return check(rule, e.Body)
+		case Repetition:
+			return check(rule, e.Body)
+		}
+		return nil
+	}
+	for _, rule := range g.Rules {
+		if err := check(rule, rule.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ebnfKind int
+
+const (
+	ebnfEOF ebnfKind = iota
+	ebnfIdentifier
+	ebnfString
+	ebnfSymbol
+)
+
+type ebnfToken struct {
+	kind  ebnfKind
+	value string
+	line  int
+}
+
+// scanEBNF separa el texto de la gramática en nombres, cadenas entre
+// comillas y los símbolos = ; | [ ] { } ( ), saltando los comentarios
+// (* ... *).
+func scanEBNF(source string) ([]ebnfToken, error) {
+	var tokens []ebnfToken
+	runes := []rune(source)
+	line := 1
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' && i+1 < len(runes) && runes[i+1] == '*':
+			start := line
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == ')') {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("línea %d: comentario sin cerrar", start)
+			}
+			i += 2
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != c && runes[end] != '\n' {
+				end++
+			}
+			if end >= len(runes) || runes[end] != c {
+				return nil, fmt.Errorf("línea %d: cadena sin cerrar", line)
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("línea %d: terminal vacío", line)
+			}
+			tokens = append(tokens, ebnfToken{ebnfString, string(runes[i+1 : end]), line})
+			i = end + 1
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			tokens = append(tokens, ebnfToken{ebnfIdentifier, string(runes[i:end]), line})
+			i = end
+		case strings.ContainsRune("=;|[]{}()", c):
+			tokens = append(tokens, ebnfToken{ebnfSymbol, string(c), line})
+			i++
+		default:
+			return nil, fmt.Errorf("línea %d: carácter inesperado %q", line, c)
+		}
+	}
+	return append(tokens, ebnfToken{ebnfEOF, "", line}), nil
+}
+
+// ebnfReader analiza la gramática por descenso recursivo:
+//
+//	rule     = NAME "=" choice ";"
+//	choice   = sequence { "|" sequence }
+//	sequence = item { item }
+//	item     = NAME | STRING | "[" choice "]" | "{" choice "}" | "(" choice ")"
+type ebnfReader struct {
+	tokens  []ebnfToken
+	current int
+}
+
+func (r *ebnfReader) peek() ebnfToken {
+	return r.tokens[r.current]
+}
+
+func (r *ebnfReader) next() ebnfToken {
+	token := r.tokens[r.current]
+	if token.kind != ebnfEOF {
+		r.current++
+	}
+	return token
+}
+
+func (r *ebnfReader) expect(symbol string) error {
+	token := r.next()
+	if token.kind != ebnfSymbol || token.value != symbol {
+		return fmt.Errorf("línea %d: se esperaba '%s' y se encontró %s", token.line, symbol, describeEBNF(token))
+	}
+	return nil
+}
+
+func (r *ebnfReader) rule() (*Rule, error) {
+	name := r.next()
+	if name.kind != ebnfIdentifier {
+		return nil, fmt.Errorf("línea %d: se esperaba el nombre de una regla y se encontró %s", name.line, describeEBNF(name))
+	}
+	if isTokenClass(name.value) {
+		return nil, fmt.Errorf("línea %d: %s es una clase de token, las reglas van en minúscula", name.line, name.value)
+	}
+	if err := r.expect("="); err != nil {
+		return nil, err
+	}
+	body, err := r.choice()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.expect(";"); err != nil {
+		return nil, err
+	}
+	return &Rule{Name: name.value, Body: body, Line: name.line}, nil
+}
+
+func (r *ebnfReader) choice() (Expr, error) {
+	var alternatives []Expr
+	for {
+		alternative, err := r.sequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, alternative)
+		
+		if token := r.peek(); token.kind != ebnfSymbol || token.value != "|" {
+			break
+		}
+		r.next()
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return Choice{Alternatives: alternatives}, nil
+}
+
+func (r *ebnfReader) sequence() (Expr, error) {
+	var items []Expr
+	for {
+		token := r.peek()
+		if token.kind == ebnfEOF || token.kind == ebnfSymbol && strings.Contains("|;)]}", token.value) {
+			break
+		}
+		item, err := r.item()
+		if err != nil {
+			return nil, err
+		}
+		// Un grupo que es solo una secuencia no agrega nada
+		if inner, ok := item.(Sequence); ok {
+			items = append(items, inner.Items...)
+		} else {
+			items = append(items, item)
+		}
+	}
+	
+	switch len(items) {
+	case 0:
+		token := r.peek()
+		return nil, fmt.Errorf("línea %d: alternativa vacía antes de %s", token.line, describeEBNF(token))
+	case 1:
+		return items[0], nil
+	}
+	return Sequence{Items: items}, nil
+}
+
+func (r *ebnfReader) item() (Expr, error) {
+	token := r.next()
+	switch token.kind {
+	case ebnfIdentifier:
+		if isTokenClass(token.value) {
+			return Terminal{Name: token.value}, nil
+		}
+		return NonTerminal{Name: token.value}, nil
+	case ebnfString:
+		return Terminal{Name: "'" + token.value + "'"}, nil
+	case ebnfSymbol:
+		closing := map[string]string{"[": "]", "{": "}", "(": ")"}[token.value]
+		if closing == "" {
+			break
+		}
+		body, err := r.choice()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.expect(closing); err != nil {
+			return nil, err
+		}
+		switch token.value {
+		case "[":
+			return Optional{Body: body}, nil
+		case "{":
+			return Repetition{Body: body}, nil
+		}
+		return body, nil
+	}
+	return nil, fmt.Errorf("línea %d: %s inesperado", token.line, describeEBNF(token))
+}
+
+// isTokenClass distingue las clases de token, en mayúsculas, de los no
+// terminales.
+func isTokenClass(name string) bool {
+	return strings.ToUpper(name) == name
+}
+
+func describeEBNF(token ebnfToken) string {
+	switch token.kind {
+	case ebnfEOF:
+		return "el fin de la gramática"
+	case ebnfString:
+		return fmt.Sprintf("%q", token.value)
+	}
+	return "'" + token.value + "'"
+}
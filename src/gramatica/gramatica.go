@@ -0,0 +1,98 @@
+// Package gramatica describe en EBNF el subconjunto de Python que reconoce
+// el parser y calcula sobre ella los conjuntos FIRST y FOLLOW y la tabla
+// LL(1), con sus conflictos, y las tablas LALR(1).
+package gramatica
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed python.ebnf
+var pythonEBNF string
+
+var python = sync.OnceValue(func() *Grammar {
+	g, err := Parse(pythonEBNF)
+	if err != nil {
+		panic("gramatica: python.ebnf: " + err.Error())
+	}
+	return g
+})
+
+// Python devuelve la gramática del subconjunto de Python, ya analizada.
+func Python() *Grammar {
+	return python()
+}
+
+// EndMarker es el terminal que marca el fin de la entrada en los conjuntos
+// FOLLOW y en la tabla.
+const EndMarker = "$"
+
+// Grammar es una gramática EBNF junto con su traducción a producciones BNF
+// y los conjuntos calculados sobre ellas. Las construcciones [ ], { } y ( )
+// de cada regla pasan a no terminales auxiliares con el nombre de la regla
+// y un número, como sum_1.
+type Grammar struct {
+	Source      string
+	Start       string
+	Rules       []*Rule
+	Productions []Production
+	
+	// No terminales en orden: cada regla seguida de sus auxiliares
+	NonTerminals []string
+	Terminals    []string
+	
+	auxiliary map[string]auxiliary
+	rules     map[string]*Rule
+	nullable  map[string]bool
+	first     map[string]set
+	follow    map[string]set
+	table     map[string]map[string][]int
+	lalr      func() *LALRTable
+}
+
+// Rule es una regla de la gramática EBNF.
+type Rule struct {
+	Name string
+	Body Expr
+	Line int
+}
+
+// Production es una producción BNF. Un cuerpo vacío es la producción ε.
+type Production struct {
+	Head string
+	Body []string
+	
+	// Regla EBNF de la que sale la producción
+	Rule string
+}
+
+func (p Production) String() string {
+	if len(p.Body) == 0 {
+		return p.Head + " → ε"
+	}
+	return p.Head + " → " + strings.Join(p.Body, " ")
+}
+
+// auxiliary recuerda de qué regla y de qué parte de ella sale un no
+// terminal auxiliar.
+type auxiliary struct {
+	rule string
+	expr Expr
+}
+
+// IsTerminal indica si el símbolo es un terminal de la gramática.
+func (g *Grammar) IsTerminal(symbol string) bool {
+	_, nonTerminal := g.first[symbol]
+	return !nonTerminal
+}
+
+// Origin describe un no terminal: para una regla devuelve su nombre, y
+// para un auxiliar la regla y el fragmento EBNF que lo originó.
+func (g *Grammar) Origin(nonTerminal string) (rule string, ebnf string) {
+	if aux, ok := g.auxiliary[nonTerminal]; ok {
+		return aux.rule, aux.expr.String()
+	}
+	return nonTerminal, ""
+}
@@ -0,0 +1,102 @@
+package gramatica
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// symbolSets son los conjuntos esperados de un no terminal.
+type symbolSets struct {
+	nullable      bool
+	first, follow []string
+}
+
+// Gramáticas de libro de texto con sus conjuntos calculados a mano. Los
+// conflictos se escriben como "no_terminal terminal" para LL(1) y como
+// "tipo terminal: acción elegida" para LALR(1).
+var grammarTests = []struct {
+	name   string
+	source string
+	sets   map[string]symbolSets
+	ll1    []string
+	lalr   []string
+}{
+	{
+		// Recursiva por la izquierda: LALR(1) pero no LL(1)
+		name:   "E/T/F recursiva por la izquierda",
+		source: `e = e "+" t | t ; t = t "*" f | f ; f = "(" e ")" | NAME ;`,
+		sets: map[string]symbolSets{
+			"e": {false, []string{"'('", "NAME"}, []string{"'+'", "')'", "$"}},
+			"t": {false, []string{"'('", "NAME"}, []string{"'+'", "'*'", "')'", "$"}},
+			"f": {false, []string{"'('", "NAME"}, []string{"'+'", "'*'", "')'", "$"}},
+		},
+		ll1: []string{"e '('", "e NAME", "t '('", "t NAME"},
+	},
+	{
+		// La misma con repeticiones, que pasan a auxiliares anulables
+		name:   "E/T/F con repeticiones",
+		source: `e = t { "+" t } ; t = f { "*" f } ; f = "(" e ")" | NAME ;`,
+		sets: map[string]symbolSets{
+			"e":   {false, []string{"'('", "NAME"}, []string{"')'", "$"}},
+			"e_1": {true, []string{"'+'"}, []string{"')'", "$"}},
+			"t":   {false, []string{"'('", "NAME"}, []string{"'+'", "')'", "$"}},
+			"t_1": {true, []string{"'*'"}, []string{"'+'", "')'", "$"}},
+			"f":   {false, []string{"'('", "NAME"}, []string{"'+'", "'*'", "')'", "$"}},
+		},
+	},
+	{
+		// El else colgante: la tabla LALR(1) desplaza
+		name:   "else colgante",
+		source: `s = "if" NAME s [ "else" s ] | NAME ;`,
+		sets: map[string]symbolSets{
+			"s":   {false, []string{"'if'", "NAME"}, []string{"'else'", "$"}},
+			"s_1": {true, []string{"'else'"}, []string{"'else'", "$"}},
+		},
+		ll1:  []string{"s_1 'else'"},
+		lalr: []string{"shift/reduce 'else': desplaza"},
+	},
+}
+
+func TestGrammarSets(t *testing.T) {
+	for _, test := range grammarTests {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := Parse(test.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(g.NonTerminals) != len(test.sets) {
+				t.Errorf("no terminales = %v", g.NonTerminals)
+			}
+			for nonTerminal, want := range test.sets {
+				got := symbolSets{g.Nullable(nonTerminal), g.First(nonTerminal), g.Follow(nonTerminal)}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: anulable, FIRST y FOLLOW = %v, se esperaba %v", nonTerminal, got, want)
+				}
+			}
+			
+			var ll1 []string
+			for _, conflict := range g.Conflicts() {
+				ll1 = append(ll1, conflict.NonTerminal+" "+conflict.Terminal)
+			}
+			if !reflect.DeepEqual(ll1, test.ll1) {
+				t.Errorf("conflictos LL(1) = %v, se esperaba %v", ll1, test.ll1)
+			}
+			
+			var lalr []string
+			for _, conflict := range g.LALR().Conflicts() {
+				// El estado destino del desplazamiento depende de la numeración
+				chosen := strings.Fields(conflict.Chosen)[0]
+				lalr = append(lalr, conflict.Kind+" "+conflict.Terminal+": "+chosen)
+			}
+			if !reflect.DeepEqual(lalr, test.lalr) {
+				t.Errorf("conflictos LALR(1) = %v, se esperaba %v", lalr, test.lalr)
+			}
+			
+			report := NewReport(g)
+			if report.LL1 != (len(test.ll1) == 0) || report.LALR != (len(test.lalr) == 0) {
+				t.Errorf("reporte: LL1 = %v, LALR = %v", report.LL1, report.LALR)
+			}
+		})
+	}
+}
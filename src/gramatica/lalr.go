@@ -0,0 +1,334 @@
+package gramatica
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Acciones de la tabla LR
+const (
+	Shift  = "shift"
+	Reduce = "reduce"
+	Accept = "accept"
+)
+
+// Action es una celda de la tabla ACTION. Target es el estado al que se
+// desplaza o el índice en Productions de la producción que se reduce.
+type Action struct {
+	Kind   string
+	Target int
+}
+
+// LRConflict es una celda de la tabla ACTION con más de una acción. La
+// tabla se queda con Chosen: entre desplazar y reducir gana desplazar, y
+// entre dos reducciones la producción que aparece antes en la gramática.
+type LRConflict struct {
+	State    int      `json:"state"`
+	Terminal string   `json:"terminal"`
+	Kind     string   `json:"kind"`
+	Actions  []string `json:"actions"`
+	Chosen   string   `json:"chosen"`
+	
+	// Ítems del núcleo del estado, para ubicar el conflicto en la gramática
+	Items []string `json:"items"`
+}
+
+// LALRTable son las tablas ACTION y GOTO LALR(1) de la gramática.
+type LALRTable struct {
+	grammar     *Grammar
+	productions []Production
+	states      []*lrState
+	action      []map[string][]Action
+	gotos       []map[string]int
+	conflicts   []LRConflict
+}
+
+// lrItem es una producción con el punto antes del símbolo Body[dot].
+type lrItem struct {
+	production int
+	dot        int
+}
+
+// lrState es un estado del autómata LR(0) con los símbolos de anticipación
+// de sus ítems del núcleo, que se fusionan entre estados con el mismo
+// núcleo como en LALR(1).
+type lrState struct {
+	kernel      []lrItem
+	lookahead   map[lrItem]set
+	transitions map[string]int
+	symbols     []string
+}
+
+// LALR devuelve las tablas LALR(1) de la gramática. Se construyen la
+// primera vez que se piden.
+func (g *Grammar) LALR() *LALRTable {
+	return g.lalr()
+}
+
+// newLALR arma el autómata sobre la gramática aumentada con S' → S. Cada
+// vez que un estado recibe anticipaciones nuevas se vuelve a propagar
+// desde él, hasta que ninguno cambia.
+func newLALR(g *Grammar) *LALRTable {
+	t := &LALRTable{grammar: g}
+	t.productions = append(g.Productions[:len(g.Productions):len(g.Productions)], Production{
+		Head: g.Start + "'",
+		Body: []string{g.Start},
+		Rule: g.Start,
+	})
+	byHead := map[string][]int{}
+	for i, p := range g.Productions {
+		byHead[p.Head] = append(byHead[p.Head], i)
+	}
+	
+	cores := map[string]int{}
+	state := func(kernel []lrItem) (int, bool) {
+		key := coreKey(kernel)
+		if index, ok := cores[key]; ok {
+			return index, false
+		}
+		cores[key] = len(t.states)
+		t.states = append(t.states, &lrState{kernel: kernel, lookahead: map[lrItem]set{}})
+		return len(t.states) - 1, true
+	}
+	
+	augmented := lrItem{production: len(g.Productions)}
+	state([]lrItem{augmented})
+	t.states[0].lookahead[augmented] = set{EndMarker: true}
+	
+	pending := []int{0}
+	queued := map[int]bool{0: true}
+	for len(pending) > 0 {
+		current := t.states[pending[0]]
+		queued[pending[0]] = false
+		pending = pending[1:]
+		
+		items, lookahead := t.closure(current, byHead)
+		kernels := map[string][]lrItem{}
+		current.symbols = current.symbols[:0]
+		for _, item := range items {
+			body := t.productions[item.production].Body
+			if item.dot == len(body) {
+				continue
+			}
+			symbol := body[item.dot]
+			if _, ok := kernels[symbol]; !ok {
+				current.symbols = append(current.symbols, symbol)
+			}
+			kernels[symbol] = append(kernels[symbol], lrItem{item.production, item.dot + 1})
+		}
+		
+		current.transitions = map[string]int{}
+		for _, symbol := range current.symbols {
+			kernel := kernels[symbol]
+			sort.Slice(kernel, func(i, j int) bool { return itemLess(kernel[i], kernel[j]) })
+			target, created := state(kernel)
+			current.transitions[symbol] = target
+			
+			grew := created
+			next := t.states[target]
+			for _, item := range kernel {
+				moved := lrItem{item.production, item.dot - 1}
+				if next.lookahead[item] == nil {
+					next.lookahead[item] = set{}
+				}
+				if next.lookahead[item].add(lookahead[moved]) {
+					grew = true
+				}
+			}
+			if grew && !queued[target] {
+				pending = append(pending, target)
+				queued[target] = true
+			}
+		}
+	}
+	
+	t.fill(byHead)
+	return t
+}
+
+// closure agrega al núcleo los ítems B → ·γ de cada A → α·Bβ, con
+// anticipación FIRST(β) y, si β es anulable, la de A → α·Bβ.
+func (t *LALRTable) closure(s *lrState, byHead map[string][]int) ([]lrItem, map[lrItem]set) {
+	items := append([]lrItem{}, s.kernel...)
+	lookahead := map[lrItem]set{}
+	for _, item := range s.kernel {
+		lookahead[item] = set{}
+		lookahead[item].add(s.lookahead[item])
+	}
+	
+	pending := append([]lrItem{}, s.kernel...)
+	for len(pending) > 0 {
+		item := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		
+		body := t.productions[item.production].Body
+		if item.dot == len(body) || t.grammar.IsTerminal(body[item.dot]) {
+			continue
+		}
+		first, nullable := t.grammar.firstOf(body[item.dot+1:])
+		if nullable {
+			first.add(lookahead[item])
+		}
+		for _, production := range byHead[body[item.dot]] {
+			added := lrItem{production: production}
+			if _, ok := lookahead[added]; !ok {
+				lookahead[added] = set{}
+				items = append(items, added)
+			}
+			if lookahead[added].add(first) {
+				pending = append(pending, added)
+			}
+		}
+	}
+	return items, lookahead
+}
+
+// fill arma las tablas ACTION y GOTO a partir del autómata y anota los
+// conflictos.
+func (t *LALRTable) fill(byHead map[string][]int) {
+	accept := len(t.grammar.Productions)
+	t.action = make([]map[string][]Action, len(t.states))
+	t.gotos = make([]map[string]int, len(t.states))
+	
+	for index, s := range t.states {
+		t.action[index] = map[string][]Action{}
+		t.gotos[index] = map[string]int{}
+		candidates := map[string][]Action{}
+		
+		for _, symbol := range s.symbols {
+			target := s.transitions[symbol]
+			if t.grammar.IsTerminal(symbol) {
+				candidates[symbol] = append(candidates[symbol], Action{Shift, target})
+			} else {
+				t.gotos[index][symbol] = target
+			}
+		}
+		items, lookahead := t.closure(s, byHead)
+		for _, item := range items {
+			if item.dot < len(t.productions[item.production].Body) {
+				continue
+			}
+			if item.production == accept {
+				candidates[EndMarker] = append(candidates[EndMarker], Action{Kind: Accept})
+				continue
+			}
+			for terminal := range lookahead[item] {
+				candidates[terminal] = append(candidates[terminal], Action{Reduce, item.production})
+			}
+		}
+		
+		for _, terminal := range t.grammar.InputTerminals() {
+			actions := candidates[terminal]
+			if len(actions) == 0 {
+				continue
+			}
+			sort.SliceStable(actions, func(i, j int) bool {
+				if actions[i].Kind != actions[j].Kind {
+					return actions[i].Kind == Shift
+				}
+				return actions[i].Target < actions[j].Target
+			})
+			t.action[index][terminal] = actions
+			if len(actions) > 1 {
+				t.conflict(index, terminal, actions)
+			}
+		}
+	}
+}
+
+func (t *LALRTable) conflict(state int, terminal string, actions []Action) {
+	conflict := LRConflict{
+		State:    state,
+		Terminal: terminal,
+		Kind:     "reduce/reduce",
+		Chosen:   t.Describe(actions[0]),
+	}
+	if actions[0].Kind == Shift {
+		conflict.Kind = "shift/reduce"
+	}
+	for _, action := range actions {
+		conflict.Actions = append(conflict.Actions, t.Describe(action))
+	}
+	for _, item := range t.states[state].kernel {
+		conflict.Items = append(conflict.Items, t.describeItem(item))
+	}
+	t.conflicts = append(t.conflicts, conflict)
+}
+
+// States devuelve la cantidad de estados del autómata.
+func (t *LALRTable) States() int {
+	return len(t.states)
+}
+
+// Action devuelve la acción del estado con ese terminal; si no hay, la
+// entrada tiene un error de sintaxis.
+func (t *LALRTable) Action(state int, terminal string) (Action, bool) {
+	actions := t.action[state][terminal]
+	if len(actions) == 0 {
+		return Action{}, false
+	}
+	return actions[0], true
+}
+
+// Actions devuelve todas las acciones de la celda, la elegida primero. Más
+// de una es un conflicto.
+func (t *LALRTable) Actions(state int, terminal string) []Action {
+	return t.action[state][terminal]
+}
+
+// Goto devuelve el estado al que se pasa después de reducir al no
+// terminal.
+func (t *LALRTable) Goto(state int, nonTerminal string) (int, bool) {
+	target, ok := t.gotos[state][nonTerminal]
+	return target, ok
+}
+
+// Expected devuelve los terminales con alguna acción en el estado.
+func (t *LALRTable) Expected(state int) []string {
+	terminals := []string{}
+	for _, terminal := range t.grammar.InputTerminals() {
+		if len(t.action[state][terminal]) > 0 {
+			terminals = append(terminals, terminal)
+		}
+	}
+	return terminals
+}
+
+// Conflicts devuelve los conflictos de la tabla ACTION por estado y en el
+// orden de los terminales. Una gramática sin conflictos es LALR(1).
+func (t *LALRTable) Conflicts() []LRConflict {
+	return append([]LRConflict{}, t.conflicts...)
+}
+
+// Describe escribe la acción como en la traza del parser.
+func (t *LALRTable) Describe(action Action) string {
+	switch action.Kind {
+	case Shift:
+		return fmt.Sprintf("desplaza %d", action.Target)
+	case Reduce:
+		return "reduce " + t.productions[action.Target].String()
+	}
+	return "acepta"
+}
+
+func (t *LALRTable) describeItem(item lrItem) string {
+	p := t.productions[item.production]
+	symbols := append(append(p.Body[:item.dot:item.dot], "·"), p.Body[item.dot:]...)
+	return p.Head + " → " + strings.Join(symbols, " ")
+}
+
+func coreKey(kernel []lrItem) string {
+	var sb strings.Builder
+	for _, item := range kernel {
+		fmt.Fprintf(&sb, "%d.%d ", item.production, item.dot)
+	}
+	return sb.String()
+}
+
+func itemLess(a, b lrItem) bool {
+	if a.production != b.production {
+		return a.production < b.production
+	}
+	return a.dot < b.dot
+}
@@ -0,0 +1,109 @@
+package gramatica
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report reúne lo que se calcula sobre la gramática para revisarla: el
+// texto EBNF, las producciones BNF, los conjuntos de cada no terminal, los
+// conflictos LL(1) y los de las tablas LALR(1).
+type Report struct {
+	EBNF        string       `json:"ebnf"`
+	Productions []string     `json:"productions"`
+	Symbols     []SymbolSets `json:"symbols"`
+	Conflicts   []Conflict   `json:"conflicts"`
+	LL1         bool         `json:"ll1"`
+	
+	LALRStates    int          `json:"lalr_states"`
+	LALRConflicts []LRConflict `json:"lalr_conflicts"`
+	LALR          bool         `json:"lalr"`
+}
+
+// SymbolSets son los conjuntos de un no terminal. Los auxiliares indican
+// la regla y el fragmento EBNF del que salen.
+type SymbolSets struct {
+	Name     string   `json:"name"`
+	Rule     string   `json:"rule"`
+	EBNF     string   `json:"ebnf,omitempty"`
+	Nullable bool     `json:"nullable"`
+	First    []string `json:"first"`
+	Follow   []string `json:"follow"`
+}
+
+// NewReport arma el reporte de la gramática.
+func NewReport(g *Grammar) Report {
+	report := Report{EBNF: g.Source, Conflicts: g.Conflicts()}
+	report.LL1 = len(report.Conflicts) == 0
+	report.LALRStates = g.LALR().States()
+	report.LALRConflicts = g.LALR().Conflicts()
+	report.LALR = len(report.LALRConflicts) == 0
+	for _, p := range g.Productions {
+		report.Productions = append(report.Productions, p.String())
+	}
+	for _, nonTerminal := range g.NonTerminals {
+		rule, ebnf := g.Origin(nonTerminal)
+		report.Symbols = append(report.Symbols, SymbolSets{
+			Name:     nonTerminal,
+			Rule:     rule,
+			EBNF:     ebnf,
+			Nullable: g.Nullable(nonTerminal),
+			First:    g.First(nonTerminal),
+			Follow:   g.Follow(nonTerminal),
+		})
+	}
+	return report
+}
+
+// Text imprime el reporte para leerlo en una terminal.
+func (r Report) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Gramática: %d no terminales, %d producciones\n\n", len(r.Symbols), len(r.Productions))
+	
+	sb.WriteString("FIRST y FOLLOW\n")
+	for _, symbol := range r.Symbols {
+		sb.WriteString("\n" + symbol.Name)
+		if symbol.EBNF != "" {
+			fmt.Fprintf(&sb, "  (de %s: %s)", symbol.Rule, symbol.EBNF)
+		}
+		if symbol.Nullable {
+			sb.WriteString("  anulable")
+		}
+		fmt.Fprintf(&sb, "\n  FIRST  = { %s }\n", strings.Join(symbol.First, " "))
+		fmt.Fprintf(&sb, "  FOLLOW = { %s }\n", strings.Join(symbol.Follow, " "))
+	}
+	
+	if r.LL1 {
+		sb.WriteString("\nLa gramática es LL(1): la tabla no tiene conflictos\n")
+	} else {
+		fmt.Fprintf(&sb, "\nConflictos LL(1): %d\n", len(r.Conflicts))
+	}
+	for _, conflict := range r.Conflicts {
+		fmt.Fprintf(&sb, "\n%s con %s", conflict.NonTerminal, conflict.Terminal)
+		if conflict.EBNF != "" {
+			fmt.Fprintf(&sb, "  (de %s: %s)", conflict.Rule, conflict.EBNF)
+		}
+		sb.WriteString("\n")
+		for _, production := range conflict.Productions {
+			sb.WriteString("  " + production + "\n")
+		}
+	}
+	
+	fmt.Fprintf(&sb, "\nTablas LALR(1): %d estados\n", r.LALRStates)
+	if r.LALR {
+		sb.WriteString("La gramática es LALR(1): las tablas no tienen conflictos\n")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "Conflictos LALR(1): %d\n", len(r.LALRConflicts))
+	for _, conflict := range r.LALRConflicts {
+		fmt.Fprintf(&sb, "\nEstado %d con %s, %s\n", conflict.State, conflict.Terminal, conflict.Kind)
+		for _, item := range conflict.Items {
+			sb.WriteString("  " + item + "\n")
+		}
+		for _, action := range conflict.Actions {
+			sb.WriteString("  → " + action + "\n")
+		}
+		sb.WriteString("  se elige: " + conflict.Chosen + "\n")
+	}
+	return sb.String()
+}
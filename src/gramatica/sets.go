@@ -0,0 +1,172 @@
+package gramatica
+
+// set es un conjunto de terminales.
+type set map[string]bool
+
+// add agrega los terminales de other y dice si el conjunto creció.
+func (s set) add(other set) bool {
+	grew := false
+	for terminal := range other {
+		if !s[terminal] {
+			s[terminal] = true
+			grew = true
+		}
+	}
+	return grew
+}
+
+// computeSets calcula los no terminales anulables y los conjuntos FIRST y
+// FOLLOW iterando hasta el punto fijo, y con ellos la tabla LL(1).
+func (g *Grammar) computeSets() {
+	g.nullable = map[string]bool{}
+	g.first = map[string]set{}
+	g.follow = map[string]set{}
+	for _, nonTerminal := range g.NonTerminals {
+		g.first[nonTerminal] = set{}
+		g.follow[nonTerminal] = set{}
+	}
+	g.follow[g.Start][EndMarker] = true
+	
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g.Productions {
+			first, nullable := g.firstOf(p.Body)
+			if g.first[p.Head].add(first) {
+				changed = true
+			}
+			if nullable && !g.nullable[p.Head] {
+				g.nullable[p.Head] = true
+				changed = true
+			}
+		}
+	}
+	
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g.Productions {
+			for i, symbol := range p.Body {
+				if g.IsTerminal(symbol) {
+					continue
+				}
+				// Lo que puede seguir a symbol es FIRST del resto de la
+				// producción y, si el resto es anulable, FOLLOW de la cabeza
+				first, nullable := g.firstOf(p.Body[i+1:])
+				if g.follow[symbol].add(first) {
+					changed = true
+				}
+				if nullable && g.follow[symbol].add(g.follow[p.Head]) {
+					changed = true
+				}
+			}
+		}
+	}
+	
+	g.table = map[string]map[string][]int{}
+	for _, nonTerminal := range g.NonTerminals {
+		g.table[nonTerminal] = map[string][]int{}
+	}
+	for i, p := range g.Productions {
+		first, nullable := g.firstOf(p.Body)
+		if nullable {
+			first.add(g.follow[p.Head])
+		}
+		for terminal := range first {
+			g.table[p.Head][terminal] = append(g.table[p.Head][terminal], i)
+		}
+	}
+}
+
+// firstOf devuelve FIRST de una secuencia de símbolos y si la secuencia
+// puede derivar ε.
+func (g *Grammar) firstOf(symbols []string) (set, bool) {
+	first := set{}
+	for _, symbol := range symbols {
+		if g.IsTerminal(symbol) {
+			first[symbol] = true
+			return first, false
+		}
+		first.add(g.first[symbol])
+		if !g.nullable[symbol] {
+			return first, false
+		}
+	}
+	return first, true
+}
+
+// Nullable indica si el no terminal deriva la cadena vacía.
+func (g *Grammar) Nullable(nonTerminal string) bool {
+	return g.nullable[nonTerminal]
+}
+
+// First devuelve el conjunto FIRST de un no terminal, con los terminales
+// en el orden en que aparecen en la gramática.
+func (g *Grammar) First(nonTerminal string) []string {
+	return g.sorted(g.first[nonTerminal])
+}
+
+// Follow devuelve el conjunto FOLLOW de un no terminal; EndMarker va al
+// final.
+func (g *Grammar) Follow(nonTerminal string) []string {
+	return g.sorted(g.follow[nonTerminal])
+}
+
+// Predict devuelve los índices de las producciones de la tabla LL(1) para
+// el no terminal con ese terminal de entrada: ninguna es un error de
+// sintaxis y más de una es un conflicto.
+func (g *Grammar) Predict(nonTerminal, terminal string) []int {
+	return g.table[nonTerminal][terminal]
+}
+
+func (g *Grammar) sorted(s set) []string {
+	terminals := []string{}
+	for _, terminal := range g.InputTerminals() {
+		if s[terminal] {
+			terminals = append(terminals, terminal)
+		}
+	}
+	return terminals
+}
+
+// InputTerminals devuelve los terminales de la gramática más EndMarker,
+// las columnas de la tabla.
+func (g *Grammar) InputTerminals() []string {
+	return append(g.Terminals[:len(g.Terminals):len(g.Terminals)], EndMarker)
+}
+
+// Conflict es una celda de la tabla LL(1) con más de una producción.
+type Conflict struct {
+	NonTerminal string   `json:"non_terminal"`
+	Terminal    string   `json:"terminal"`
+	Productions []string `json:"productions"`
+	
+	// Regla EBNF y, para un auxiliar, el fragmento que lo originó
+	Rule string `json:"rule"`
+	EBNF string `json:"ebnf,omitempty"`
+}
+
+// Conflicts devuelve los conflictos de la tabla LL(1) en el orden de la
+// gramática. Una gramática sin conflictos es LL(1).
+func (g *Grammar) Conflicts() []Conflict {
+	conflicts := []Conflict{}
+	for _, nonTerminal := range g.NonTerminals {
+		for _, terminal := range g.InputTerminals() {
+			predicted := g.table[nonTerminal][terminal]
+			if len(predicted) < 2 {
+				continue
+			}
+			productions := make([]string, len(predicted))
+			for i, index := range predicted {
+				productions[i] = g.Productions[index].String()
+			}
+			rule, ebnf := g.Origin(nonTerminal)
+			conflicts = append(conflicts, Conflict{
+				NonTerminal: nonTerminal,
+				Terminal:    terminal,
+				Productions: productions,
+				Rule:        rule,
+				EBNF:        ebnf,
+			})
+		}
+	}
+	return conflicts
+}
@@ -17,6 +17,9 @@ const (
 	WHITESPACE
 	NEWLINE
 	ERROR
+	INDENT
+	DEDENT
+	EOF
 )
 
 type Token struct {
@@ -49,11 +52,15 @@ var pythonKeywords = map[string]bool{
     "with": true, "as": true, "pass": true, "break": true, "continue": true,
     "return": true, "yield": true, "import": true, "from": true, "class": true,
     "and": true, "or": true, "not": true, "is": true, "lambda": true,
-    "None": true, "True": true, "False": true, "print": true,
+    "None": true, "True": true, "False": true, "print": true, "raise": true,
+    "async": true, "await": true, "global": true, "nonlocal": true,
+    "del": true, "assert": true,
 }
 
 var pythonSymbols = []string{
+	"**=", "//=", ">>=", "<<=", "...",
 	"==", "!=", "<=", ">=", ">>", "<<", "**", "//", "+=", "-=", "*=", "/=",
+	"%=", "&=", "|=", "^=", "@=", ":=", "->",
 	"=", "+", "-", "*", "/", "%", "<", ">", "(", ")", "[", "]", "{", "}",
 	":", ";", ",", ".", "&", "|", "^", "~", "!", "@", "#", "$", "?",
 }
@@ -214,7 +221,22 @@ func (r *LexicalResult) processIdentifier(text string, line, column int) (Token,
 }
 
 func (r *LexicalResult) processSymbol(text string, line, column int) (Token, int) {
-	// Verificar símbolos de dos caracteres primero
+	// Verificar símbolos de tres caracteres primero
+	if len(text) >= 3 {
+		threeChar := text[:3]
+		for _, symbol := range pythonSymbols {
+			if symbol == threeChar {
+				return Token{
+					Type:   SYMBOL,
+					Value:  threeChar,
+					Line:   line,
+					Column: column,
+				}, 3
+			}
+		}
+	}
+	
+	// Luego los de dos caracteres
 	if len(text) >= 2 {
 		twoChar := text[:2]
 		for _, symbol := range pythonSymbols {
@@ -0,0 +1,569 @@
+package parser
+
+import "examencorte2/src/lexer"
+
+// Position es la ubicación de un nodo en el código fuente: desde el
+// comienzo de su primer token hasta el final del último. Como en Span, las
+// columnas empiezan en 1 y el final no se incluye.
+type Position struct {
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+func (p Position) Pos() Position {
+	return p
+}
+
+// Span devuelve la posición con la forma de los diagnósticos.
+func (p Position) Span() Span {
+	return Span{Line: p.Line, Column: p.Column, EndLine: p.EndLine, EndColumn: p.EndColumn}
+}
+
+// tokenPosition ubica un nodo de un solo token.
+func tokenPosition(token lexer.Token) Position {
+	return Position{
+		Line:      token.Line,
+		Column:    token.Column,
+		EndLine:   token.Line,
+		EndColumn: token.Column + len(token.Value),
+	}
+}
+
+// through devuelve la posición que va desde el comienzo de start hasta el
+// final de end.
+func through(start, end Position) Position {
+	return Position{
+		Line:      start.Line,
+		Column:    start.Column,
+		EndLine:   end.EndLine,
+		EndColumn: end.EndColumn,
+	}
+}
+
+// Node es cualquier nodo del árbol sintáctico.
+type Node interface {
+	Pos() Position
+}
+
+// Stmt es una sentencia.
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr es una expresión.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Pattern es un patrón de una cláusula case.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// Module es la raíz del árbol: las sentencias del programa.
+type Module struct {
+	Position
+	Body []Stmt
+}
+
+// Block es un bloque indentado o la sentencia escrita tras ':' en la misma
+// línea. También aparece como sentencia cuando hay una indentación
+// inesperada.
+type Block struct {
+	Position
+	Body []Stmt
+}
+
+// Sentencias
+
+// BadStmt reemplaza a una sentencia con error de sintaxis. Blocks son los
+// bloques indentados que la seguían, analizados igual para informar sus
+// propios errores.
+type BadStmt struct {
+	Position
+	Message string
+	Blocks  []*Block
+}
+
+type FunctionDef struct {
+	Position
+	Name       string
+	Decorators []*Decorator
+	Params     []*Parameter
+	Returns    Expr
+	Body       *Block
+	Async      bool
+}
+
+type Decorator struct {
+	Position
+	Expr Expr
+}
+
+// ParamKind indica cómo se puede pasar un argumento a un parámetro.
+type ParamKind int
+
+const (
+	RegularParam ParamKind = iota
+	PositionalOnlyParam
+	KeywordOnlyParam
+	VarArgsParam
+	KwArgsParam
+)
+
+func (k ParamKind) String() string {
+	switch k {
+	case PositionalOnlyParam:
+		return "PositionalOnlyParameter"
+	case KeywordOnlyParam:
+		return "KeywordOnlyParameter"
+	case VarArgsParam:
+		return "VarArgs"
+	case KwArgsParam:
+		return "KwArgs"
+	}
+	return "Parameter"
+}
+
+type Parameter struct {
+	Position
+	Name       string
+	Kind       ParamKind
+	Annotation Expr
+	Default    Expr
+}
+
+type ClassDef struct {
+	Position
+	Name       string
+	Decorators []*Decorator
+	Bases      []Expr
+	Keywords   []*Keyword
+	Body       *Block
+}
+
+type If struct {
+	Position
+	Test Expr
+	Body *Block
+}
+
+type For struct {
+	Position
+	Target Expr
+	Iter   Expr
+	Body   *Block
+	Else   *Clause
+	Async  bool
+}
+
+// Clause es una cláusula else o finally con su bloque.
+type Clause struct {
+	Position
+	Body *Block
+}
+
+type Try struct {
+	Position
+	Body     *Block
+	Handlers []*ExceptHandler
+	Else     *Clause
+	Finally  *Clause
+}
+
+// ExceptHandler es "except [Tipo [as nombre]]:". Type es nil en un except
+// sin tipo y Name está vacío si no se liga la excepción.
+type ExceptHandler struct {
+	Position
+	Type Expr
+	Name string
+	Body *Block
+}
+
+type Raise struct {
+	Position
+	Exc   Expr
+	Cause Expr
+}
+
+type With struct {
+	Position
+	Items []*WithItem
+	Body  *Block
+	Async bool
+}
+
+type WithItem struct {
+	Position
+	Context Expr
+	Target  Expr
+}
+
+type Match struct {
+	Position
+	Subject Expr
+	Cases   []*MatchCase
+}
+
+type MatchCase struct {
+	Position
+	Pattern Pattern
+	Guard   Expr
+	Body    *Block
+}
+
+// Assign es "a = b = valor": varios destinos y un valor.
+type Assign struct {
+	Position
+	Targets []Expr
+	Value   Expr
+}
+
+type AugAssign struct {
+	Position
+	Target Expr
+	Op     string
+	Value  Expr
+}
+
+// AnnAssign es "destino: tipo [= valor]". Value es nil si no hay valor.
+type AnnAssign struct {
+	Position
+	Target     Expr
+	Annotation Expr
+	Value      Expr
+}
+
+type ExprStmt struct {
+	Position
+	Value Expr
+}
+
+type Pass struct {
+	Position
+}
+
+type Global struct {
+	Position
+	Names []*Identifier
+}
+
+type Nonlocal struct {
+	Position
+	Names []*Identifier
+}
+
+type Delete struct {
+	Position
+	Targets []Expr
+}
+
+type Assert struct {
+	Position
+	Test Expr
+	Msg  Expr
+}
+
+// Expresiones
+
+type Identifier struct {
+	Position
+	Name string
+}
+
+type Number struct {
+	Position
+	Value string
+}
+
+type String struct {
+	Position
+	Value string
+}
+
+type Boolean struct {
+	Position
+	Value bool
+}
+
+type None struct {
+	Position
+}
+
+type Ellipsis struct {
+	Position
+}
+
+type List struct {
+	Position
+	Elts []Expr
+}
+
+type Tuple struct {
+	Position
+	Elts []Expr
+}
+
+type Set struct {
+	Position
+	Elts []Expr
+}
+
+// Dict guarda claves y valores en paralelo. Una clave nil corresponde a
+// "**otro", cuyo diccionario está en Values.
+type Dict struct {
+	Position
+	Keys   []Expr
+	Values []Expr
+}
+
+type Starred struct {
+	Position
+	Value Expr
+}
+
+type Subscript struct {
+	Position
+	Value Expr
+	Index Expr
+}
+
+// Slice es inicio:fin:paso; las partes omitidas son nil.
+type Slice struct {
+	Position
+	Lower Expr
+	Upper Expr
+	Step  Expr
+}
+
+type Attribute struct {
+	Position
+	Value Expr
+	Attr  string
+}
+
+// Call es una llamada. Args son los argumentos posicionales, incluidos los
+// *iterable, y Keywords los argumentos por nombre y los **diccionario.
+type Call struct {
+	Position
+	Func     Expr
+	Args     []Expr
+	Keywords []*Keyword
+}
+
+// Keyword es nombre=valor en una llamada o en las bases de una clase. Con
+// Name vacío representa **valor.
+type Keyword struct {
+	Position
+	Name  string
+	Value Expr
+}
+
+type UnaryOp struct {
+	Position
+	Op      string
+	Operand Expr
+}
+
+// BinaryOp es una operación aritmética, de bits o una comparación simple.
+type BinaryOp struct {
+	Position
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// Compare es una cadena de comparaciones como a < b <= c.
+type Compare struct {
+	Position
+	Left        Expr
+	Ops         []string
+	Comparators []Expr
+}
+
+type BoolOp struct {
+	Position
+	Op     string
+	Values []Expr
+}
+
+// IfExp es "Body if Test else Orelse".
+type IfExp struct {
+	Position
+	Test   Expr
+	Body   Expr
+	Orelse Expr
+}
+
+type Lambda struct {
+	Position
+	Params []*Parameter
+	Body   Expr
+}
+
+type NamedExpr struct {
+	Position
+	Target *Identifier
+	Value  Expr
+}
+
+type ListComp struct {
+	Position
+	Elt        Expr
+	Generators []*Comprehension
+}
+
+type SetComp struct {
+	Position
+	Elt        Expr
+	Generators []*Comprehension
+}
+
+type GeneratorExp struct {
+	Position
+	Elt        Expr
+	Generators []*Comprehension
+}
+
+type DictComp struct {
+	Position
+	Key        Expr
+	Value      Expr
+	Generators []*Comprehension
+}
+
+// Comprehension es una cláusula "[async] for destino in iterable if ...".
+type Comprehension struct {
+	Position
+	Target Expr
+	Iter   Expr
+	Ifs    []Expr
+	Async  bool
+}
+
+type Await struct {
+	Position
+	Value Expr
+}
+
+// Patrones
+
+// MatchValue compara con un literal o un valor con punto como Color.ROJO.
+type MatchValue struct {
+	Position
+	Value Expr
+}
+
+// MatchSingleton compara por identidad con None, True o False.
+type MatchSingleton struct {
+	Position
+	Value string
+}
+
+type MatchSequence struct {
+	Position
+	Patterns []Pattern
+}
+
+// MatchStar es "*nombre" dentro de una secuencia; Name está vacío en "*_".
+type MatchStar struct {
+	Position
+	Name string
+}
+
+// MatchMapping es "{clave: patrón, **resto}". Keys y Patterns van en
+// paralelo y Rest está vacío si no hay **resto.
+type MatchMapping struct {
+	Position
+	Keys     []Pattern
+	Patterns []Pattern
+	Rest     string
+}
+
+type MatchClass struct {
+	Position
+	Cls      Expr
+	Patterns []Pattern
+	Keywords []*MatchKeyword
+}
+
+type MatchKeyword struct {
+	Position
+	Name    string
+	Pattern Pattern
+}
+
+// MatchAs es una captura, el comodín _ (Name vacío y sin Pattern) o
+// "patrón as nombre".
+type MatchAs struct {
+	Position
+	Pattern Pattern
+	Name    string
+}
+
+type MatchOr struct {
+	Position
+	Patterns []Pattern
+}
+
+func (*Block) stmtNode()       {}
+func (*BadStmt) stmtNode()     {}
+func (*FunctionDef) stmtNode() {}
+func (*ClassDef) stmtNode()    {}
+func (*If) stmtNode()          {}
+func (*For) stmtNode()         {}
+func (*Try) stmtNode()         {}
+func (*Raise) stmtNode()       {}
+func (*With) stmtNode()        {}
+func (*Match) stmtNode()       {}
+func (*Assign) stmtNode()      {}
+func (*AugAssign) stmtNode()   {}
+func (*AnnAssign) stmtNode()   {}
+func (*ExprStmt) stmtNode()    {}
+func (*Pass) stmtNode()        {}
+func (*Global) stmtNode()      {}
+func (*Nonlocal) stmtNode()    {}
+func (*Delete) stmtNode()      {}
+func (*Assert) stmtNode()      {}
+
+func (*Identifier) exprNode()   {}
+func (*Number) exprNode()       {}
+func (*String) exprNode()       {}
+func (*Boolean) exprNode()      {}
+func (*None) exprNode()         {}
+func (*Ellipsis) exprNode()     {}
+func (*List) exprNode()         {}
+func (*Tuple) exprNode()        {}
+func (*Set) exprNode()          {}
+func (*Dict) exprNode()         {}
+func (*Starred) exprNode()      {}
+func (*Subscript) exprNode()    {}
+func (*Slice) exprNode()        {}
+func (*Attribute) exprNode()    {}
+func (*Call) exprNode()         {}
+func (*UnaryOp) exprNode()      {}
+func (*BinaryOp) exprNode()     {}
+func (*Compare) exprNode()      {}
+func (*BoolOp) exprNode()       {}
+func (*IfExp) exprNode()        {}
+func (*Lambda) exprNode()       {}
+func (*NamedExpr) exprNode()    {}
+func (*ListComp) exprNode()     {}
+func (*SetComp) exprNode()      {}
+func (*GeneratorExp) exprNode() {}
+func (*DictComp) exprNode()     {}
+func (*Await) exprNode()        {}
+
+func (*MatchValue) patternNode()     {}
+func (*MatchSingleton) patternNode() {}
+func (*MatchSequence) patternNode()  {}
+func (*MatchStar) patternNode()      {}
+func (*MatchMapping) patternNode()   {}
+func (*MatchClass) patternNode()     {}
+func (*MatchAs) patternNode()        {}
+func (*MatchOr) patternNode()        {}
@@ -0,0 +1,1237 @@
+package parser
+
+import "examencorte2/src/gramatica"
+
+// treeBuilder arma el árbol tipado a partir de un árbol de derivación sobre
+// las producciones de src/gramatica, como el que construyen los parsers
+// dirigidos por tabla. Los nodos se ubican en las mismas posiciones que
+// les da el descenso recursivo, así que para un programa válido el
+// resultado es el mismo que el de Analyze. En errors quedan las
+// restricciones de rules.go que el árbol incumple.
+type treeBuilder struct {
+	grammar *gramatica.Grammar
+	errors  []Diagnostic
+}
+
+func newTreeBuilder(grammar *gramatica.Grammar) *treeBuilder {
+	return &treeBuilder{grammar: grammar}
+}
+
+// symbols recorre los hijos de un nodo de una regla EBNF como una secuencia:
+// los no terminales auxiliares de sus [ ], { } y ( ) se reemplazan por sus
+// propios hijos.
+type symbols struct {
+	nodes   []*ParseTree
+	current int
+}
+
+func (b *treeBuilder) children(node *ParseTree) *symbols {
+	var flatten func(node *ParseTree) []*ParseTree
+	flatten = func(node *ParseTree) []*ParseTree {
+		var nodes []*ParseTree
+		for _, child := range node.Children {
+			if rule, _ := b.grammar.Origin(child.Symbol); !child.terminal && rule != child.Symbol {
+				nodes = append(nodes, flatten(child)...)
+			} else {
+				nodes = append(nodes, child)
+			}
+		}
+		return nodes
+	}
+	return &symbols{nodes: flatten(node)}
+}
+
+// check indica si el próximo hijo es el símbolo dado.
+func (s *symbols) check(symbol string) bool {
+	return s.current < len(s.nodes) && s.nodes[s.current].Symbol == symbol
+}
+
+// match consume el próximo hijo si es el símbolo dado.
+func (s *symbols) match(symbol string) *ParseTree {
+	if !s.check(symbol) {
+		return nil
+	}
+	return s.next()
+}
+
+func (s *symbols) next() *ParseTree {
+	if s.current >= len(s.nodes) {
+		return nil
+	}
+	s.current++
+	return s.nodes[s.current-1]
+}
+
+// items devuelve los nodos item de una lista recursiva por la derecha como
+// parameters = parameter [ "," [ parameters ] ], e indica si tenía alguna
+// coma.
+func (b *treeBuilder) items(list *ParseTree, item string) ([]*ParseTree, bool) {
+	var nodes []*ParseTree
+	comma := false
+	for list != nil {
+		s := b.children(list)
+		next := list
+		list = nil
+		for node := s.next(); node != nil; node = s.next() {
+			switch node.Symbol {
+			case item:
+				nodes = append(nodes, node)
+			case "','":
+				comma = true
+			case next.Symbol:
+				list = node
+			}
+		}
+	}
+	return nodes, comma
+}
+
+// first y last ubican el primer y el último token de un nodo sin contar
+// los saltos de línea ni la indentación, como spanFrom; ok es falso si el
+// nodo no tiene tokens.
+func first(node *ParseTree) (position Position, ok bool) {
+	if node.terminal {
+		return node.position, !isLayout(node.Symbol)
+	}
+	for _, child := range node.Children {
+		if position, ok := first(child); ok {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+func last(node *ParseTree) (position Position, ok bool) {
+	if node.terminal {
+		return node.position, !isLayout(node.Symbol)
+	}
+	for i := len(node.Children) - 1; i >= 0; i-- {
+		if position, ok := last(node.Children[i]); ok {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+func isLayout(symbol string) bool {
+	return symbol == "NEWLINE" || symbol == "INDENT" || symbol == "DEDENT"
+}
+
+// span ubica los tokens desde el comienzo de from hasta el final de to.
+func span(from, to *ParseTree) Position {
+	start, _ := first(from)
+	end, _ := last(to)
+	return through(start, end)
+}
+
+// violated registra la restricción incumplida, si la hay, sobre su nodo o,
+// si no tiene, sobre los tokens de at.
+func (b *treeBuilder) violated(v *violation, at *ParseTree) bool {
+	if v == nil {
+		return false
+	}
+	position := span(at, at)
+	if v.node != nil {
+		position = v.node.Pos()
+	}
+	b.errors = append(b.errors, Diagnostic{Code: v.code, Message: v.message, Span: position.Span()})
+	return true
+}
+
+// report registra un diagnóstico sobre los tokens de at.
+func (b *treeBuilder) report(at *ParseTree, code, message string) {
+	b.violated(&violation{code: code, message: message}, at)
+}
+
+func (b *treeBuilder) module(node *ParseTree) *Module {
+	module := &Module{
+		Position: Position{Line: 1, Column: 1, EndLine: 1, EndColumn: 1},
+		Body:     []Stmt{},
+	}
+	s := b.children(node)
+	for statement := s.next(); statement != nil; statement = s.next() {
+		module.Body = append(module.Body, b.statement(statement))
+	}
+	if end, ok := last(node); ok {
+		module.Position = through(module.Position, end)
+	}
+	return module
+}
+
+// Sentencias
+
+func (b *treeBuilder) statement(node *ParseTree) Stmt {
+	child := node.Children[0]
+	switch child.Symbol {
+	case "funcdef":
+		return b.funcdef(child)
+	case "classdef":
+		return b.classdef(child)
+	case "decorated":
+		return b.decorated(child)
+	case "async_stmt":
+		return b.asyncStmt(child)
+	case "if_stmt":
+		return b.ifStmt(child)
+	case "for_stmt":
+		return b.forStmt(child)
+	case "try_stmt":
+		return b.tryStmt(child)
+	case "with_stmt":
+		return b.withStmt(child)
+	case "match_stmt":
+		return b.matchStmt(child)
+	}
+	return b.simpleStmt(child)
+}
+
+func (b *treeBuilder) simpleStmt(node *ParseTree) Stmt {
+	child := b.children(node).next()
+	switch child.Symbol {
+	case "'pass'":
+		return &Pass{Position: child.position}
+	case "raise_stmt":
+		return b.raiseStmt(child)
+	case "global_stmt", "nonlocal_stmt":
+		return b.nameDeclaration(child)
+	case "del_stmt":
+		return b.delStmt(child)
+	case "assert_stmt":
+		return b.assertStmt(child)
+	}
+	return b.exprStmt(child)
+}
+
+func (b *treeBuilder) block(node *ParseTree) *Block {
+	s := b.children(node)
+	block := &Block{Position: span(node, node), Body: []Stmt{}}
+	if !s.check("NEWLINE") {
+		block.Body = append(block.Body, b.simpleStmt(s.next()))
+		return block
+	}
+	
+	s.next()
+	s.next()
+	for statement := s.match("statement"); statement != nil; statement = s.match("statement") {
+		block.Body = append(block.Body, b.statement(statement))
+	}
+	return block
+}
+
+func (b *treeBuilder) funcdef(node *ParseTree) *FunctionDef {
+	s := b.children(node)
+	s.next()
+	function := &FunctionDef{Position: span(node, node), Name: s.next().Value}
+	s.next()
+	function.Params = b.parameters(s.match("parameters"), true)
+	s.next()
+	if s.match("'->'") != nil {
+		function.Returns = b.expression(s.next())
+	}
+	s.next()
+	function.Body = b.block(s.next())
+	return function
+}
+
+// parameters arma los parámetros de una función o, sin anotaciones, de una
+// lambda. Como parseParameters, '/' vuelve solo posicionales a los
+// anteriores y después de '*' son solo por nombre.
+func (b *treeBuilder) parameters(list *ParseTree, annotations bool) []*Parameter {
+	item := "parameter"
+	if !annotations {
+		item = "lambda_parameter"
+	}
+	nodes, _ := b.items(list, item)
+	
+	params := []*Parameter{}
+	rules := newParameterRules()
+	for i, node := range nodes {
+		s := b.children(node)
+		switch {
+		case s.match("'/'") != nil:
+			b.violated(rules.slash(params), node)
+			for _, param := range params {
+				param.Kind = PositionalOnlyParam
+			}
+			
+		case s.match("'**'") != nil:
+			param := b.parameter(s, KwArgsParam, rules)
+			b.violated(rules.kwargs(param, i == len(nodes)-1), node)
+			params = append(params, param)
+			
+		case s.match("'*'") != nil:
+			b.violated(rules.star(), node)
+			if s.check("NAME") {
+				params = append(params, b.parameter(s, VarArgsParam, rules))
+			}
+			
+		default:
+			param := b.parameter(s, rules.kind, rules)
+			if s.match("'='") != nil {
+				param.Default = b.expression(s.next())
+			}
+			b.violated(rules.regular(param), node)
+			params = append(params, param)
+		}
+	}
+	b.violated(rules.end(params), list)
+	return params
+}
+
+func (b *treeBuilder) parameter(s *symbols, kind ParamKind, rules *parameterRules) *Parameter {
+	name := s.next()
+	b.violated(rules.name(name.Value), name)
+	param := &Parameter{Position: name.position, Name: name.Value, Kind: kind}
+	if s.match("':'") != nil {
+		annotation := s.next()
+		param.Position = span(name, annotation)
+		param.Annotation = b.expression(annotation)
+	}
+	return param
+}
+
+func (b *treeBuilder) decorated(node *ParseTree) Stmt {
+	s := b.children(node)
+	decorators := []*Decorator{}
+	for sign := s.match("'@'"); sign != nil; sign = s.match("'@'") {
+		expr := s.next()
+		decorators = append(decorators, &Decorator{Position: span(sign, expr), Expr: b.namedExpression(expr)})
+		s.next()
+	}
+	
+	if async := s.match("'async'"); async != nil {
+		definition := s.next()
+		function := b.funcdef(definition)
+		function.Position = span(async, definition)
+		function.Async = true
+		function.Decorators = decorators
+		return function
+	}
+	if definition := s.match("classdef"); definition != nil {
+		class := b.classdef(definition)
+		class.Decorators = decorators
+		return class
+	}
+	function := b.funcdef(s.next())
+	function.Decorators = decorators
+	return function
+}
+
+func (b *treeBuilder) asyncStmt(node *ParseTree) Stmt {
+	s := b.children(node)
+	s.next()
+	child := s.next()
+	// La sentencia empieza en async
+	switch child.Symbol {
+	case "for_stmt":
+		stmt := b.forStmt(child)
+		stmt.Position = span(node, node)
+		stmt.Async = true
+		return stmt
+	case "with_stmt":
+		stmt := b.withStmt(child)
+		stmt.Position = span(node, node)
+		stmt.Async = true
+		return stmt
+	}
+	stmt := b.funcdef(child)
+	stmt.Position = span(node, node)
+	stmt.Async = true
+	return stmt
+}
+
+func (b *treeBuilder) classdef(node *ParseTree) *ClassDef {
+	s := b.children(node)
+	s.next()
+	class := &ClassDef{Position: span(node, node), Name: s.next().Value}
+	if open := s.match("'('"); open != nil {
+		list := s.match("arguments")
+		class.Bases, class.Keywords = b.arguments(list, span(open, s.next()))
+	}
+	s.next()
+	class.Body = b.block(s.next())
+	return class
+}
+
+func (b *treeBuilder) ifStmt(node *ParseTree) *If {
+	s := b.children(node)
+	s.next()
+	ifNode := &If{Position: span(node, node), Test: b.namedExpression(s.next())}
+	s.next()
+	ifNode.Body = b.block(s.next())
+	return ifNode
+}
+
+func (b *treeBuilder) forStmt(node *ParseTree) *For {
+	s := b.children(node)
+	s.next()
+	forNode := &For{Position: span(node, node), Target: b.starTargets(s.next())}
+	s.next()
+	forNode.Iter = b.starExpressions(s.next())
+	s.next()
+	forNode.Body = b.block(s.next())
+	if elseBlock := s.match("else_block"); elseBlock != nil {
+		forNode.Else = b.clause(elseBlock)
+	}
+	return forNode
+}
+
+// clause arma un else_block o las palabras "finally" ":" block que siguen
+// en s.
+func (b *treeBuilder) clause(node *ParseTree) *Clause {
+	return b.clauseFrom(b.children(node))
+}
+
+func (b *treeBuilder) clauseFrom(s *symbols) *Clause {
+	keyword := s.next()
+	s.next()
+	block := s.next()
+	return &Clause{Position: span(keyword, block), Body: b.block(block)}
+}
+
+func (b *treeBuilder) tryStmt(node *ParseTree) *Try {
+	s := b.children(node)
+	s.next()
+	tryNode := &Try{Position: span(node, node)}
+	s.next()
+	tryNode.Body = b.block(s.next())
+	for handler := s.match("except_block"); handler != nil; handler = s.match("except_block") {
+		if n := len(tryNode.Handlers); n > 0 && tryNode.Handlers[n-1].Type == nil {
+			b.report(handler, ErrInvalidStatement, "El 'except:' sin tipo debe ser el último manejador")
+		}
+		tryNode.Handlers = append(tryNode.Handlers, b.exceptBlock(handler))
+	}
+	if elseBlock := s.match("else_block"); elseBlock != nil {
+		if len(tryNode.Handlers) == 0 {
+			b.report(elseBlock, ErrInvalidStatement, "La cláusula 'else' de try requiere al menos un 'except'")
+		}
+		tryNode.Else = b.clause(elseBlock)
+	}
+	if s.check("'finally'") {
+		tryNode.Finally = b.clauseFrom(s)
+	}
+	if len(tryNode.Handlers) == 0 && tryNode.Finally == nil {
+		b.report(node, ErrExpectedToken, "Se esperaba 'except' o 'finally' después del bloque try")
+	}
+	return tryNode
+}
+
+func (b *treeBuilder) exceptBlock(node *ParseTree) *ExceptHandler {
+	s := b.children(node)
+	s.next()
+	handler := &ExceptHandler{Position: span(node, node)}
+	if s.check("expression") {
+		handler.Type = b.expression(s.next())
+		if s.match("'as'") != nil {
+			handler.Name = s.next().Value
+		}
+	}
+	s.next()
+	handler.Body = b.block(s.next())
+	return handler
+}
+
+func (b *treeBuilder) withStmt(node *ParseTree) *With {
+	s := b.children(node)
+	s.next()
+	withNode := &With{Position: span(node, node)}
+	if s.match("'('") != nil {
+		items, _ := b.items(s.next(), "with_item")
+		for _, item := range items {
+			withNode.Items = append(withNode.Items, b.withItem(item))
+		}
+		s.next()
+	}
+	for item := s.match("with_item"); item != nil; item = s.match("with_item") {
+		withNode.Items = append(withNode.Items, b.withItem(item))
+		s.match("','")
+	}
+	s.next()
+	withNode.Body = b.block(s.next())
+	return withNode
+}
+
+func (b *treeBuilder) withItem(node *ParseTree) *WithItem {
+	s := b.children(node)
+	item := &WithItem{Position: span(node, node), Context: b.expression(s.next())}
+	if s.match("'as'") != nil {
+		target := s.next()
+		item.Target = b.expression(target)
+		if !b.violated(starredViolation(item.Target), target) {
+			b.violated(targetViolation(item.Target), target)
+		}
+	}
+	return item
+}
+
+func (b *treeBuilder) matchStmt(node *ParseTree) *Match {
+	s := b.children(node)
+	s.next()
+	matchNode := &Match{Position: span(node, node)}
+	first := s.next()
+	subject := b.starNamedExpression(first)
+	if end := s.match("','"); end != nil {
+		elements := []Expr{subject}
+		if list := s.match("star_named_expressions"); list != nil {
+			elements = append(elements, b.starNamedExpressions(list)...)
+			end = list
+		}
+		subject = &Tuple{Position: span(first, end), Elts: elements}
+	}
+	matchNode.Subject = subject
+	
+	s.next()
+	s.next()
+	s.next()
+	for matchCase := s.match("case_block"); matchCase != nil; matchCase = s.match("case_block") {
+		matchNode.Cases = append(matchNode.Cases, b.caseBlock(matchCase))
+	}
+	return matchNode
+}
+
+func (b *treeBuilder) caseBlock(node *ParseTree) *MatchCase {
+	s := b.children(node)
+	s.next()
+	caseNode := &MatchCase{Position: span(node, node), Pattern: b.patterns(s.next())}
+	if s.match("'if'") != nil {
+		caseNode.Guard = b.namedExpression(s.next())
+	}
+	s.next()
+	caseNode.Body = b.block(s.next())
+	return caseNode
+}
+
+func (b *treeBuilder) raiseStmt(node *ParseTree) *Raise {
+	s := b.children(node)
+	s.next()
+	raise := &Raise{Position: span(node, node)}
+	if s.check("expression") {
+		raise.Exc = b.expression(s.next())
+		if s.match("'from'") != nil {
+			raise.Cause = b.expression(s.next())
+		}
+	}
+	return raise
+}
+
+func (b *treeBuilder) nameDeclaration(node *ParseTree) Stmt {
+	s := b.children(node)
+	keyword := s.next()
+	names := []*Identifier{}
+	for name := s.match("NAME"); name != nil; name = s.match("NAME") {
+		names = append(names, &Identifier{Position: name.position, Name: name.Value})
+		s.match("','")
+	}
+	if keyword.Value == "nonlocal" {
+		return &Nonlocal{Position: span(node, node), Names: names}
+	}
+	return &Global{Position: span(node, node), Names: names}
+}
+
+func (b *treeBuilder) delStmt(node *ParseTree) *Delete {
+	s := b.children(node)
+	s.next()
+	del := &Delete{Position: span(node, node)}
+	targets, _ := b.items(s.next(), "bitwise_or")
+	for _, target := range targets {
+		expr := b.expression(target)
+		b.violated(deleteViolation(expr), target)
+		del.Targets = append(del.Targets, expr)
+	}
+	return del
+}
+
+func (b *treeBuilder) assertStmt(node *ParseTree) *Assert {
+	s := b.children(node)
+	s.next()
+	assert := &Assert{Position: span(node, node), Test: b.expression(s.next())}
+	if s.match("','") != nil {
+		assert.Msg = b.expression(s.next())
+	}
+	return assert
+}
+
+func (b *treeBuilder) exprStmt(node *ParseTree) Stmt {
+	s := b.children(node)
+	expr := b.starExpressions(s.next())
+	
+	if annassign := s.match("annassign"); annassign != nil {
+		b.violated(annAssignViolation(expr), node)
+		a := b.children(annassign)
+		a.next()
+		ann := &AnnAssign{Position: span(node, node), Target: expr, Annotation: b.expression(a.next())}
+		if a.match("'='") != nil {
+			ann.Value = b.starExpressions(a.next())
+		}
+		return ann
+	}
+	
+	if s.check("'='") {
+		assign := &Assign{Position: span(node, node)}
+		for equals := s.match("'='"); equals != nil; equals = s.match("'='") {
+			b.violated(targetViolation(expr), equals)
+			assign.Targets = append(assign.Targets, expr)
+			expr = b.starExpressions(s.next())
+		}
+		assign.Value = expr
+		return assign
+	}
+	
+	if operator := s.next(); operator != nil {
+		b.violated(augAssignViolation(operator.Value, expr), operator)
+		return &AugAssign{
+			Position: span(node, node),
+			Target:   expr,
+			Op:       operator.Value,
+			Value:    b.starExpressions(s.next()),
+		}
+	}
+	return &ExprStmt{Position: span(node, node), Value: expr}
+}
+
+// Patrones
+
+func (b *treeBuilder) patterns(node *ParseTree) Pattern {
+	s := b.children(node)
+	first := b.maybeStarPattern(s.next())
+	if _, star := first.(*MatchStar); !s.check("','") && !star {
+		return first
+	}
+	
+	patterns := []Pattern{first}
+	s.next()
+	if list := s.match("maybe_star_patterns"); list != nil {
+		patterns = append(patterns, b.maybeStarPatterns(list)...)
+	}
+	b.violated(sequenceViolation(patterns), node)
+	return &MatchSequence{Position: span(node, node), Patterns: patterns}
+}
+
+func (b *treeBuilder) maybeStarPatterns(list *ParseTree) []Pattern {
+	nodes, _ := b.items(list, "maybe_star_pattern")
+	patterns := []Pattern{}
+	for _, node := range nodes {
+		patterns = append(patterns, b.maybeStarPattern(node))
+	}
+	return patterns
+}
+
+func (b *treeBuilder) maybeStarPattern(node *ParseTree) Pattern {
+	s := b.children(node)
+	star := s.match("'*'")
+	if star == nil {
+		return b.pattern(s.next())
+	}
+	name := s.next().Value
+	if name == "_" {
+		name = ""
+	}
+	return &MatchStar{Position: span(node, node), Name: name}
+}
+
+func (b *treeBuilder) pattern(node *ParseTree) Pattern {
+	s := b.children(node)
+	closed := s.next()
+	alternatives := []Pattern{b.closedPattern(closed)}
+	for s.match("'|'") != nil {
+		closed = s.next()
+		alternatives = append(alternatives, b.closedPattern(closed))
+	}
+	
+	pattern := alternatives[0]
+	if len(alternatives) > 1 {
+		pattern = &MatchOr{Position: span(node, closed), Patterns: alternatives}
+	}
+	if s.match("'as'") == nil {
+		return pattern
+	}
+	name := s.next()
+	if name.Value == "_" {
+		b.report(name, ErrExpectedName, "Se esperaba un nombre después de 'as' en el patrón")
+	}
+	return &MatchAs{Position: span(node, node), Pattern: pattern, Name: name.Value}
+}
+
+func (b *treeBuilder) closedPattern(node *ParseTree) Pattern {
+	s := b.children(node)
+	position := span(node, node)
+	first := s.nodes[0]
+	
+	switch first.Symbol {
+	case "'None'", "'True'", "'False'":
+		return &MatchSingleton{Position: position, Value: first.Value}
+		
+	case "'-'", "NUMBER", "STRING":
+		return &MatchValue{Position: position, Value: b.literal(s)}
+		
+	case "'('":
+		s.next()
+		list := s.match("maybe_star_patterns")
+		if list == nil {
+			return &MatchSequence{Position: position, Patterns: []Pattern{}}
+		}
+		patterns := b.maybeStarPatterns(list)
+		// (patrón) solo agrupa; con coma es una secuencia
+		if _, comma := b.items(list, "maybe_star_pattern"); !comma {
+			if _, star := patterns[0].(*MatchStar); !star {
+				return patterns[0]
+			}
+		}
+		b.violated(sequenceViolation(patterns), node)
+		return &MatchSequence{Position: position, Patterns: patterns}
+		
+	case "'['":
+		s.next()
+		patterns := []Pattern{}
+		if list := s.match("maybe_star_patterns"); list != nil {
+			patterns = b.maybeStarPatterns(list)
+		}
+		b.violated(sequenceViolation(patterns), node)
+		return &MatchSequence{Position: position, Patterns: patterns}
+		
+	case "mapping_pattern":
+		return b.mappingPattern(first)
+	}
+	
+	name := s.next()
+	if !s.check("'.'") && !s.check("'('") {
+		if name.Value == "_" {
+			return &MatchAs{Position: position}
+		}
+		return &MatchAs{Position: position, Name: name.Value}
+	}
+	value := b.dottedName(s, name)
+	if s.match("'('") == nil {
+		return &MatchValue{Position: position, Value: value}
+	}
+	
+	class := &MatchClass{Position: position, Cls: value}
+	for list := s.match("class_arguments"); list != nil; {
+		a := b.children(list)
+		if name := a.match("NAME"); name != nil {
+			a.next()
+			pattern := a.next()
+			class.Keywords = append(class.Keywords, &MatchKeyword{
+				Position: span(name, pattern),
+				Name:     name.Value,
+				Pattern:  b.pattern(pattern),
+			})
+		} else {
+			pattern := a.next()
+			if len(class.Keywords) > 0 {
+				b.report(pattern, ErrInvalidPattern, "Patrón posicional después de un patrón por nombre")
+			}
+			class.Patterns = append(class.Patterns, b.pattern(pattern))
+		}
+		a.match("','")
+		list = a.match("class_arguments")
+	}
+	return class
+}
+
+// dottedName arma el valor Nombre.atributo... de un patrón; el nombre ya
+// fue leído.
+func (b *treeBuilder) dottedName(s *symbols, name *ParseTree) Expr {
+	var value Expr = &Identifier{Position: name.position, Name: name.Value}
+	for s.match("'.'") != nil {
+		attr := s.next()
+		value = &Attribute{Position: span(name, attr), Value: value, Attr: attr.Value}
+	}
+	return value
+}
+
+// literal arma el número, el número negativo o el string de un patrón.
+func (b *treeBuilder) literal(s *symbols) Expr {
+	if minus := s.match("'-'"); minus != nil {
+		number := s.next()
+		return &UnaryOp{
+			Position: span(minus, number),
+			Op:       "-",
+			Operand:  &Number{Position: number.position, Value: number.Value},
+		}
+	}
+	token := s.next()
+	if token.Symbol == "STRING" {
+		return &String{Position: token.position, Value: token.Value}
+	}
+	return &Number{Position: token.position, Value: token.Value}
+}
+
+func (b *treeBuilder) mappingPattern(node *ParseTree) Pattern {
+	s := b.children(node)
+	s.next()
+	mapping := &MatchMapping{Position: span(node, node)}
+	for list := s.match("mapping_items"); list != nil; {
+		m := b.children(list)
+		if m.match("'**'") != nil {
+			rest := m.next()
+			if rest.Value == "_" {
+				b.report(rest, ErrExpectedName, "Se esperaba un nombre después de '**' en el patrón")
+			}
+			mapping.Rest = rest.Value
+			break
+		}
+		mapping.Keys = append(mapping.Keys, b.mappingKey(m.next()))
+		m.next()
+		mapping.Patterns = append(mapping.Patterns, b.pattern(m.next()))
+		m.match("','")
+		list = m.match("mapping_items")
+	}
+	return mapping
+}
+
+func (b *treeBuilder) mappingKey(node *ParseTree) Pattern {
+	s := b.children(node)
+	position := span(node, node)
+	switch first := s.nodes[0]; first.Symbol {
+	case "'None'", "'True'", "'False'":
+		return &MatchSingleton{Position: position, Value: first.Value}
+	case "NAME":
+		return &MatchValue{Position: position, Value: b.dottedName(s, s.next())}
+	}
+	return &MatchValue{Position: position, Value: b.literal(s)}
+}
+
+// Expresiones
+
+// expression arma cualquier no terminal de expresión: cada regla de la
+// cadena de precedencia con un único hijo deja pasar el de abajo.
+func (b *treeBuilder) expression(node *ParseTree) Expr {
+	switch node.Symbol {
+	case "star_expressions":
+		return b.starExpressions(node)
+	case "star_expression", "star_named_expression", "star_target":
+		return b.starred(node)
+	case "named_expression":
+		return b.namedExpression(node)
+	case "lambdef":
+		return b.lambdef(node)
+	case "disjunction", "conjunction":
+		return b.boolOp(node)
+	case "inversion", "factor":
+		return b.unaryOp(node)
+	case "comparison":
+		return b.comparison(node)
+	case "power":
+		return b.power(node)
+	case "await_primary":
+		return b.awaitPrimary(node)
+	case "atom_expr":
+		return b.atomExpr(node)
+	case "atom":
+		return b.atom(node)
+	case "bitwise_or", "bitwise_xor", "bitwise_and", "shift_expr", "sum", "term":
+		return b.binaryOp(node)
+	}
+	
+	// expression = lambdef | disjunction [ "if" disjunction "else" expression ]
+	s := b.children(node)
+	expr := b.expression(s.next())
+	if s.match("'if'") == nil {
+		return expr
+	}
+	test := b.expression(s.next())
+	s.next()
+	return &IfExp{
+		Position: span(node, node),
+		Test:     test,
+		Body:     expr,
+		Orelse:   b.expression(s.next()),
+	}
+}
+
+// starExpressions arma una lista de expresiones separadas por comas, que
+// con más de una o con coma final es una tupla sin paréntesis.
+func (b *treeBuilder) starExpressions(node *ParseTree) Expr {
+	items, comma := b.items(node, "star_expression")
+	first := b.expression(items[0])
+	if !comma {
+		b.violated(starredViolation(first), node)
+		return first
+	}
+	tuple := &Tuple{Position: span(node, node), Elts: []Expr{first}}
+	for _, item := range items[1:] {
+		tuple.Elts = append(tuple.Elts, b.expression(item))
+	}
+	return tuple
+}
+
+func (b *treeBuilder) starNamedExpressions(node *ParseTree) []Expr {
+	items, _ := b.items(node, "star_named_expression")
+	elements := []Expr{}
+	for _, item := range items {
+		elements = append(elements, b.expression(item))
+	}
+	return elements
+}
+
+func (b *treeBuilder) starNamedExpression(node *ParseTree) Expr {
+	return b.starred(node)
+}
+
+func (b *treeBuilder) starred(node *ParseTree) Expr {
+	s := b.children(node)
+	star := s.match("'*'")
+	value := b.expression(s.next())
+	if star == nil {
+		return value
+	}
+	return &Starred{Position: span(node, node), Value: value}
+}
+
+// starTargets arma los destinos de un for o de una comprensión.
+func (b *treeBuilder) starTargets(node *ParseTree) Expr {
+	items, comma := b.items(node, "star_target")
+	target := b.expression(items[0])
+	if comma {
+		tuple := &Tuple{Position: span(node, node), Elts: []Expr{target}}
+		for _, item := range items[1:] {
+			tuple.Elts = append(tuple.Elts, b.expression(item))
+		}
+		target = tuple
+	}
+	if !b.violated(starredViolation(target), node) {
+		b.violated(targetViolation(target), node)
+	}
+	return target
+}
+
+func (b *treeBuilder) namedExpression(node *ParseTree) Expr {
+	s := b.children(node)
+	name := s.match("NAME")
+	if name == nil {
+		return b.expression(s.next())
+	}
+	s.next()
+	return &NamedExpr{
+		Position: span(node, node),
+		Target:   &Identifier{Position: name.position, Name: name.Value},
+		Value:    b.expression(s.next()),
+	}
+}
+
+func (b *treeBuilder) lambdef(node *ParseTree) Expr {
+	s := b.children(node)
+	s.next()
+	lambda := &Lambda{Position: span(node, node)}
+	lambda.Params = b.parameters(s.match("lambda_parameters"), false)
+	s.next()
+	lambda.Body = b.expression(s.next())
+	return lambda
+}
+
+func (b *treeBuilder) boolOp(node *ParseTree) Expr {
+	s := b.children(node)
+	expr := b.expression(s.next())
+	if s.current == len(s.nodes) {
+		return expr
+	}
+	boolOp := &BoolOp{Position: span(node, node), Values: []Expr{expr}}
+	for operator := s.next(); operator != nil; operator = s.next() {
+		boolOp.Op = operator.Value
+		boolOp.Values = append(boolOp.Values, b.expression(s.next()))
+	}
+	return boolOp
+}
+
+// unaryOp arma "not" inversion y los signos de factor.
+func (b *treeBuilder) unaryOp(node *ParseTree) Expr {
+	s := b.children(node)
+	first := s.next()
+	if !first.terminal {
+		return b.expression(first)
+	}
+	return &UnaryOp{
+		Position: span(node, node),
+		Op:       first.Value,
+		Operand:  b.expression(s.next()),
+	}
+}
+
+// comparison arma una comparación simple como BinaryOp y una cadena como
+// Compare. "not in" e "is not" son dos terminales.
+func (b *treeBuilder) comparison(node *ParseTree) Expr {
+	s := b.children(node)
+	expr := b.expression(s.next())
+	operators := []string{}
+	comparators := []Expr{}
+	for operator := s.next(); operator != nil; operator = s.next() {
+		op := operator.Value
+		if s.check("'in'") || s.check("'not'") {
+			op += " " + s.next().Value
+		}
+		operators = append(operators, op)
+		comparators = append(comparators, b.expression(s.next()))
+	}
+	
+	switch len(operators) {
+	case 0:
+		return expr
+	case 1:
+		return &BinaryOp{Position: span(node, node), Op: operators[0], Left: expr, Right: comparators[0]}
+	}
+	return &Compare{Position: span(node, node), Left: expr, Ops: operators, Comparators: comparators}
+}
+
+// binaryOp arma las operaciones asociativas por la izquierda de las reglas
+// x = y { op y }.
+func (b *treeBuilder) binaryOp(node *ParseTree) Expr {
+	s := b.children(node)
+	expr := b.expression(s.next())
+	for operator := s.next(); operator != nil; operator = s.next() {
+		right := s.next()
+		expr = &BinaryOp{
+			Position: span(node, right),
+			Op:       operator.Value,
+			Left:     expr,
+			Right:    b.expression(right),
+		}
+	}
+	return expr
+}
+
+func (b *treeBuilder) power(node *ParseTree) Expr {
+	s := b.children(node)
+	base := b.expression(s.next())
+	if s.match("'**'") == nil {
+		return base
+	}
+	return &BinaryOp{Position: span(node, node), Op: "**", Left: base, Right: b.expression(s.next())}
+}
+
+func (b *treeBuilder) awaitPrimary(node *ParseTree) Expr {
+	s := b.children(node)
+	await := s.match("'await'")
+	value := b.expression(s.next())
+	if await == nil {
+		return value
+	}
+	return &Await{Position: span(node, node), Value: value}
+}
+
+// atomExpr aplica al átomo las llamadas, atributos e índices que lo siguen.
+// Cada uno ocupa desde el comienzo del átomo hasta su cierre.
+func (b *treeBuilder) atomExpr(node *ParseTree) Expr {
+	s := b.children(node)
+	expr := b.expression(s.next())
+	for trailer := s.next(); trailer != nil; trailer = s.next() {
+		t := b.children(trailer)
+		position := span(node, trailer)
+		switch t.next().Symbol {
+		case "'('":
+			call := &Call{Position: position, Func: expr}
+			call.Args, call.Keywords = b.arguments(t.match("arguments"), span(trailer, trailer))
+			expr = call
+		case "'.'":
+			expr = &Attribute{Position: position, Value: expr, Attr: t.next().Value}
+		default:
+			expr = &Subscript{Position: position, Value: expr, Index: b.slices(t.next())}
+		}
+	}
+	return expr
+}
+
+// arguments arma los argumentos de una llamada o las bases de una clase,
+// separando los posicionales de los por nombre. Un generador sin
+// paréntesis es el único argumento y ocupa los paréntesis, que están en
+// group.
+func (b *treeBuilder) arguments(list *ParseTree, group Position) ([]Expr, []*Keyword) {
+	args := []Expr{}
+	keywords := []*Keyword{}
+	rules := newArgumentRules()
+	nodes, _ := b.items(list, "argument")
+	for _, node := range nodes {
+		s := b.children(node)
+		switch first := s.next(); first.Symbol {
+		case "'**'":
+			keyword := &Keyword{Position: span(node, node), Value: b.expression(s.next())}
+			b.violated(rules.add(keyword), node)
+			keywords = append(keywords, keyword)
+		case "NAME":
+			s.next()
+			keyword := &Keyword{
+				Position: span(node, node),
+				Name:     first.Value,
+				Value:    b.expression(s.next()),
+			}
+			b.violated(rules.add(keyword), node)
+			keywords = append(keywords, keyword)
+		case "'*'":
+			arg := &Starred{Position: span(node, node), Value: b.expression(s.next())}
+			b.violated(rules.add(arg), node)
+			args = append(args, arg)
+		default:
+			arg := b.expression(first)
+			b.violated(rules.add(arg), node)
+			if clauses := s.match("for_if_clauses"); clauses != nil {
+				if len(nodes) > 1 {
+					b.violated(generatorViolation(), node)
+				}
+				b.violated(comprehensionViolation([]Expr{arg}), node)
+				arg = &GeneratorExp{Position: group, Elt: arg, Generators: b.comprehension(clauses)}
+			}
+			args = append(args, arg)
+		}
+	}
+	return args, keywords
+}
+
+// slices arma el índice de un Subscript: con coma es una tupla.
+func (b *treeBuilder) slices(node *ParseTree) Expr {
+	items, comma := b.items(node, "slice")
+	index := b.slice(items[0])
+	if !comma {
+		return index
+	}
+	tuple := &Tuple{Position: span(node, node), Elts: []Expr{index}}
+	for _, item := range items[1:] {
+		tuple.Elts = append(tuple.Elts, b.slice(item))
+	}
+	return tuple
+}
+
+func (b *treeBuilder) slice(node *ParseTree) Expr {
+	s := b.children(node)
+	parts := []Expr{nil, nil, nil}
+	if !s.check("':'") {
+		parts[0] = b.expression(s.next())
+		if !s.check("':'") {
+			return parts[0]
+		}
+	}
+	for i := 0; s.current < len(s.nodes); {
+		if s.match("':'") != nil {
+			i++
+			continue
+		}
+		parts[i] = b.expression(s.next())
+	}
+	return &Slice{Position: span(node, node), Lower: parts[0], Upper: parts[1], Step: parts[2]}
+}
+
+func (b *treeBuilder) atom(node *ParseTree) Expr {
+	s := b.children(node)
+	first := s.next()
+	position := span(node, node)
+	switch first.Symbol {
+	case "NAME", "'print'":
+		return &Identifier{Position: position, Name: first.Value}
+	case "NUMBER":
+		return &Number{Position: position, Value: first.Value}
+	case "STRING":
+		return &String{Position: position, Value: first.Value}
+	case "'True'", "'False'":
+		return &Boolean{Position: position, Value: first.Value == "True"}
+	case "'None'":
+		return &None{Position: position}
+	case "'...'":
+		return &Ellipsis{Position: position}
+	case "'{'":
+		if content := s.match("dict_or_set"); content != nil {
+			return b.dictOrSet(position, content)
+		}
+		return &Dict{Position: position}
+	}
+	
+	// "(" y "[": tupla, expresión agrupada, lista o comprensión
+	if s.check("')'") {
+		return &Tuple{Position: position, Elts: []Expr{}}
+	}
+	if s.check("']'") {
+		return &List{Position: position, Elts: []Expr{}}
+	}
+	element := b.expression(s.next())
+	if clauses := s.match("for_if_clauses"); clauses != nil {
+		b.violated(comprehensionViolation([]Expr{element}), node)
+		if first.Symbol == "'['" {
+			return &ListComp{Position: position, Elt: element, Generators: b.comprehension(clauses)}
+		}
+		return &GeneratorExp{Position: position, Elt: element, Generators: b.comprehension(clauses)}
+	}
+	
+	elements := []Expr{element}
+	comma := s.match("','") != nil
+	if list := s.match("star_named_expressions"); list != nil {
+		elements = append(elements, b.starNamedExpressions(list)...)
+	}
+	if first.Symbol == "'['" {
+		return &List{Position: position, Elts: elements}
+	}
+	if comma {
+		return &Tuple{Position: position, Elts: elements}
+	}
+	b.violated(starredViolation(element), node)
+	return element
+}
+
+// dictOrSet arma lo que va entre llaves: el primer elemento decide si es
+// un diccionario o un conjunto.
+func (b *treeBuilder) dictOrSet(position Position, node *ParseTree) Expr {
+	s := b.children(node)
+	dict := &Dict{Position: position}
+	if s.match("'**'") != nil {
+		dict.Keys = append(dict.Keys, nil)
+		dict.Values = append(dict.Values, b.expression(s.next()))
+		s.match("','")
+		return b.dictItems(dict, s.match("dict_items"))
+	}
+	
+	first := b.expression(s.next())
+	if s.match("':'") != nil {
+		value := b.expression(s.next())
+		if clauses := s.match("for_if_clauses"); clauses != nil {
+			return &DictComp{Position: position, Key: first, Value: value, Generators: b.comprehension(clauses)}
+		}
+		dict.Keys = append(dict.Keys, first)
+		dict.Values = append(dict.Values, value)
+		s.match("','")
+		return b.dictItems(dict, s.match("dict_items"))
+	}
+	
+	if clauses := s.match("for_if_clauses"); clauses != nil {
+		b.violated(comprehensionViolation([]Expr{first}), node)
+		return &SetComp{Position: position, Elt: first, Generators: b.comprehension(clauses)}
+	}
+	elements := []Expr{first}
+	s.match("','")
+	if list := s.match("star_named_expressions"); list != nil {
+		elements = append(elements, b.starNamedExpressions(list)...)
+	}
+	return &Set{Position: position, Elts: elements}
+}
+
+func (b *treeBuilder) dictItems(dict *Dict, list *ParseTree) Expr {
+	for list != nil {
+		s := b.children(list)
+		if s.match("'**'") != nil {
+			dict.Keys = append(dict.Keys, nil)
+			dict.Values = append(dict.Values, b.expression(s.next()))
+		} else {
+			dict.Keys = append(dict.Keys, b.expression(s.next()))
+			s.next()
+			dict.Values = append(dict.Values, b.expression(s.next()))
+		}
+		s.match("','")
+		list = s.match("dict_items")
+	}
+	return dict
+}
+
+func (b *treeBuilder) comprehension(node *ParseTree) []*Comprehension {
+	generators := []*Comprehension{}
+	s := b.children(node)
+	for clause := s.next(); clause != nil; clause = s.next() {
+		c := b.children(clause)
+		generator := &Comprehension{Position: span(clause, clause)}
+		generator.Async = c.match("'async'") != nil
+		c.next()
+		generator.Target = b.starTargets(c.next())
+		c.next()
+		generator.Iter = b.expression(c.next())
+		for c.match("'if'") != nil {
+			generator.Ifs = append(generator.Ifs, b.expression(c.next()))
+		}
+		generators = append(generators, generator)
+	}
+	return generators
+}
@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"strings"
+	
+	"examencorte2/src/lexer"
+)
+
+// ParseTree es un nodo del árbol de derivación (árbol sintáctico concreto).
+// Los nodos internos son los no terminales de la gramática con la
+// producción que se aplicó, y las hojas son los tokens consumidos,
+// incluidos NEWLINE, INDENT y DEDENT. A diferencia del AST conserva toda la
+// cadena de reglas: una constante suelta pasa por expression, disjunction,
+// conjunction... hasta atom.
+type ParseTree struct {
+	Symbol     string       `json:"symbol"`
+	Production string       `json:"production,omitempty"`
+	Value      string       `json:"value,omitempty"`
+	Line       int          `json:"line"`
+	Children   []*ParseTree `json:"children,omitempty"`
+	
+	terminal bool
+	position Position
+}
+
+// AnalyzeWithDerivation es como Analyze pero además registra el árbol de
+// derivación y la derivación por la izquierda que le corresponde.
+func AnalyzeWithDerivation(tokens []lexer.Token) SyntaxResult {
+	return analyze(tokens, true)
+}
+
+// rule abre un nodo del árbol de derivación para la regla que analiza la
+// función que la llama; se usa como defer p.rule("regla")(). Sin registro
+// de derivación no hace nada.
+func (p *Parser) rule(symbol string) func() {
+	return p.ruleAfter(symbol, 0)
+}
+
+// ruleAfter es como rule para las funciones que se llaman después de
+// consumir el comienzo de su regla: el nodo adopta los últimos adopt hijos
+// del nodo actual, como la palabra def en funcdef o el átomo al que se
+// aplican los trailers.
+func (p *Parser) ruleAfter(symbol string, adopt int) func() {
+	if p.derivation == nil {
+		return func() {}
+	}
+	
+	parent := p.derivation[len(p.derivation)-1]
+	split := len(parent.Children) - min(adopt, len(parent.Children))
+	node := &ParseTree{Symbol: symbol}
+	node.Children = append(node.Children, parent.Children[split:]...)
+	parent.Children = append(parent.Children[:split], node)
+	
+	// Cerrar por profundidad también cierra los nodos que quedaron abiertos
+	// por un return anticipado dentro de un ciclo
+	depth := len(p.derivation)
+	p.derivation = append(p.derivation, node)
+	return func() {
+		p.derivation = p.derivation[:depth]
+	}
+}
+
+// recordToken agrega un token consumido como hoja del nodo actual.
+func (p *Parser) recordToken(token lexer.Token) {
+	if p.derivation == nil {
+		return
+	}
+	parent := p.derivation[len(p.derivation)-1]
+	parent.Children = append(parent.Children, &ParseTree{
+		Symbol:   terminalSymbol(token),
+		Value:    token.Value,
+		Line:     token.Line,
+		terminal: true,
+		position: tokenPosition(token),
+	})
+}
+
+// terminalSymbol nombra un token como terminal de la gramática: las clases
+// de token van en mayúsculas y las palabras y símbolos entre comillas.
+func terminalSymbol(token lexer.Token) string {
+	switch token.Type {
+	case lexer.IDENTIFIER:
+		return "NAME"
+	case lexer.NUMBER:
+		return "NUMBER"
+	case lexer.STRING:
+		return "STRING"
+	case lexer.NEWLINE, lexer.INDENT, lexer.DEDENT:
+		return tokenDescription(token)
+	}
+	return "'" + token.Value + "'"
+}
+
+// finishParseTree quita los no terminales que no derivaron ningún token,
+// que quedan cuando una regla falla al comenzar, y completa la producción
+// y la línea de los demás. Devuelve false si el nodo quedó vacío.
+func finishParseTree(node *ParseTree) bool {
+	if node.terminal {
+		return true
+	}
+	
+	children := node.Children[:0]
+	symbols := []string{}
+	for _, child := range node.Children {
+		if finishParseTree(child) {
+			children = append(children, child)
+			symbols = append(symbols, child.Symbol)
+		}
+	}
+	node.Children = children
+	if len(children) == 0 {
+		return false
+	}
+	
+	node.Line = children[0].Line
+	node.Production = node.Symbol + " → " + strings.Join(symbols, " ")
+	return true
+}
+
+// DerivationStep es un paso de la derivación por la izquierda: el no
+// terminal en Position de la forma sentencial anterior se reemplaza por
+// Body. Las formas se rehacen a partir del símbolo inicial aplicando los
+// pasos; escritas completas crecerían con el cuadrado del programa.
+type DerivationStep struct {
+	Position int      `json:"position"`
+	Body     []string `json:"body"`
+}
+
+// finishGrammarTree completa la línea de los no terminales del árbol de un
+// parser dirigido por tabla, que ya tiene sus producciones. A diferencia de
+// finishParseTree conserva los no terminales que derivan ε: son pasos de
+// la derivación sobre la gramática.
+func finishGrammarTree(node *ParseTree) {
+	for _, child := range node.Children {
+		finishGrammarTree(child)
+		if node.Line == 0 {
+			node.Line = child.Line
+		}
+	}
+}
+
+// LeftmostDerivation devuelve los pasos de la derivación por la izquierda
+// del árbol. Como siempre se expande el no terminal de más a la izquierda,
+// los pasos siguen el recorrido en preorden y todo lo que queda a la
+// izquierda del no terminal son los terminales ya derivados.
+func LeftmostDerivation(root *ParseTree) []DerivationStep {
+	if root == nil {
+		return nil
+	}
+	
+	steps := []DerivationStep{}
+	terminals := 0
+	var expand func(node *ParseTree)
+	expand = func(node *ParseTree) {
+		if node.terminal {
+			terminals++
+			return
+		}
+		body := make([]string, len(node.Children))
+		for i, child := range node.Children {
+			body[i] = child.Symbol
+		}
+		steps = append(steps, DerivationStep{Position: terminals, Body: body})
+		for _, child := range node.Children {
+			expand(child)
+		}
+	}
+	expand(root)
+	return steps
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	
+	"examencorte2/src/gramatica"
+	"examencorte2/src/lexer"
+)
+
+// TestDerivationRuleNames verifica lo que afirma python.ebnf: los nodos del
+// árbol de derivación de Analyze llevan nombres de reglas de la gramática.
+func TestDerivationRuleNames(t *testing.T) {
+	rules := map[string]bool{}
+	for _, rule := range gramatica.Python().Rules {
+		rules[rule.Name] = true
+	}
+	
+	sources, err := filepath.Glob(filepath.Join("testdata", "dump", "*.py"))
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("no se encontraron programas en testdata/dump: %v", err)
+	}
+	for _, source := range sources {
+		code, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := AnalyzeWithDerivation(lexer.Analyze(string(code)).Tokens)
+		checkRuleNames(t, source, result.ParseTree, rules)
+	}
+}
+
+func checkRuleNames(t *testing.T, source string, node *ParseTree, rules map[string]bool) {
+	if node.terminal {
+		return
+	}
+	if !rules[node.Symbol] {
+		t.Errorf("%s, línea %d: %s no es una regla de la gramática", source, node.Line, node.Symbol)
+	}
+	for _, child := range node.Children {
+		checkRuleNames(t, source, child, rules)
+	}
+}
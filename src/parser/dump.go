@@ -0,0 +1,812 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DumpOptions corresponde a los argumentos indent e include_attributes de
+// ast.dump.
+type DumpOptions struct {
+	// Espacios por nivel. Sin valor, como indent=None, todo queda en una
+	// línea; con 0 cada campo va en su línea sin sangría
+	Indent *int
+	
+	// Agrega lineno, col_offset, end_lineno y end_col_offset a las
+	// sentencias, expresiones y patrones
+	Attributes bool
+}
+
+// Dump imprime el árbol igual que ast.dump de Python 3.11, con los nombres
+// de nodo y campos de CPython (BinOp, Call, Expr...). Los nombres llevan su
+// contexto Load, Store o Del y las comparaciones simples se imprimen como
+// Compare. Una sentencia con error, que CPython no puede representar, se
+// imprime como Error(message=...). Sin árbol, como el Module de AnalyzeLL1
+// cuando hay un error de sintaxis, devuelve "".
+func Dump(node Node, options DumpOptions) string {
+	if isNil(node) {
+		return ""
+	}
+	d := &dumper{attributes: options.Attributes}
+	
+	var value any
+	switch n := node.(type) {
+	case *Module:
+		value = &dumpNode{class: "Module", fields: []dumpField{
+			{"body", d.stmts(n.Body)},
+			{"type_ignores", []any{}},
+		}}
+	case *Block:
+		value = d.stmts(n.Body)
+	case Stmt:
+		value = d.stmt(n)
+	case Expr:
+		value = d.expr(n, "Load")
+	case Pattern:
+		value = d.pattern(n)
+	case *Parameter:
+		value = d.arg(n)
+	case *Keyword:
+		value = d.keyword(n)
+	case *Comprehension:
+		value = d.comprehension(n)
+	case *ExceptHandler:
+		value = d.handler(n)
+	case *WithItem:
+		value = d.withItem(n)
+	case *MatchCase:
+		value = d.matchCase(n)
+	default:
+		return ""
+	}
+	
+	if options.Indent == nil {
+		text, _ := formatDump(value, "", false, 0)
+		return text
+	}
+	text, _ := formatDump(value, strings.Repeat(" ", *options.Indent), true, 0)
+	return text
+}
+
+// dumpNode es un nodo tal como lo imprime ast.dump. Los valores de los
+// campos son *dumpNode, []any o el repr de un valor simple; un campo con
+// valor nil es opcional y se omite.
+type dumpNode struct {
+	class      string
+	fields     []dumpField
+	attributes []dumpField
+}
+
+type dumpField struct {
+	name  string
+	value any
+}
+
+// formatDump sigue a _format de ast.dump: un nodo cabe en una línea si
+// todos sus campos son simples y tiene a lo sumo tres. El segundo resultado
+// indica si el valor es simple.
+func formatDump(value any, indent string, multiline bool, level int) (string, bool) {
+	prefix, sep := "", ", "
+	if multiline {
+		level++
+		prefix = "\n" + strings.Repeat(indent, level)
+		sep = ",\n" + strings.Repeat(indent, level)
+	}
+	
+	switch v := value.(type) {
+	case *dumpNode:
+		args := []string{}
+		allSimple := true
+		for _, field := range append(v.fields, v.attributes...) {
+			if field.value == nil {
+				continue
+			}
+			text, simple := formatDump(field.value, indent, multiline, level)
+			allSimple = allSimple && simple
+			args = append(args, field.name+"="+text)
+		}
+		if allSimple && len(args) <= 3 {
+			return v.class + "(" + strings.Join(args, ", ") + ")", len(args) == 0
+		}
+		return v.class + "(" + prefix + strings.Join(args, sep) + ")", false
+	case []any:
+		if len(v) == 0 {
+			return "[]", true
+		}
+		items := []string{}
+		for _, item := range v {
+			text, _ := formatDump(item, indent, multiline, level)
+			items = append(items, text)
+		}
+		return "[" + prefix + strings.Join(items, sep) + "]", false
+	case string:
+		return v, true
+	}
+	return "None", true
+}
+
+type dumper struct {
+	attributes bool
+}
+
+// node crea un nodo con atributos, que en CPython tienen las sentencias,
+// expresiones, patrones, arg, keyword y excepthandler.
+func (d *dumper) node(n Node, class string, fields ...dumpField) *dumpNode {
+	result := &dumpNode{class: class, fields: fields}
+	if d.attributes {
+		// Las columnas de CPython empiezan en 0
+		position := n.Pos()
+		result.attributes = []dumpField{
+			{"lineno", strconv.Itoa(position.Line)},
+			{"col_offset", strconv.Itoa(position.Column - 1)},
+			{"end_lineno", strconv.Itoa(position.EndLine)},
+			{"end_col_offset", strconv.Itoa(position.EndColumn - 1)},
+		}
+	}
+	return result
+}
+
+// stmts devuelve una lista de sentencias. Un bloque con indentación
+// inesperada aporta sus sentencias al nivel actual.
+func (d *dumper) stmts(body []Stmt) []any {
+	result := []any{}
+	for _, stmt := range body {
+		if block, ok := stmt.(*Block); ok {
+			result = append(result, d.stmts(block.Body)...)
+			continue
+		}
+		result = append(result, d.stmt(stmt))
+	}
+	return result
+}
+
+func (d *dumper) block(block *Block) []any {
+	if block == nil {
+		return []any{}
+	}
+	return d.stmts(block.Body)
+}
+
+func (d *dumper) clause(c *Clause) []any {
+	if c == nil {
+		return []any{}
+	}
+	return d.block(c.Body)
+}
+
+func (d *dumper) stmt(stmt Stmt) any {
+	switch n := stmt.(type) {
+	case *Block:
+		return d.stmts(n.Body)
+	case *BadStmt:
+		return d.node(n, "Error", dumpField{"message", pythonString(n.Message)})
+		
+	case *FunctionDef:
+		class := "FunctionDef"
+		if n.Async {
+			class = "AsyncFunctionDef"
+		}
+		return d.node(n, class,
+			dumpField{"name", pythonString(n.Name)},
+			dumpField{"args", d.arguments(n.Params)},
+			dumpField{"body", d.block(n.Body)},
+			dumpField{"decorator_list", d.decorators(n.Decorators)},
+			dumpField{"returns", d.optional(n.Returns)},
+		)
+	case *ClassDef:
+		keywords := []any{}
+		for _, keyword := range n.Keywords {
+			keywords = append(keywords, d.keyword(keyword))
+		}
+		return d.node(n, "ClassDef",
+			dumpField{"name", pythonString(n.Name)},
+			dumpField{"bases", d.exprs(n.Bases, "Load")},
+			dumpField{"keywords", keywords},
+			dumpField{"body", d.block(n.Body)},
+			dumpField{"decorator_list", d.decorators(n.Decorators)},
+		)
+	case *If:
+		return d.node(n, "If",
+			dumpField{"test", d.expr(n.Test, "Load")},
+			dumpField{"body", d.block(n.Body)},
+			dumpField{"orelse", []any{}},
+		)
+	case *For:
+		class := "For"
+		if n.Async {
+			class = "AsyncFor"
+		}
+		return d.node(n, class,
+			dumpField{"target", d.expr(n.Target, "Store")},
+			dumpField{"iter", d.expr(n.Iter, "Load")},
+			dumpField{"body", d.block(n.Body)},
+			dumpField{"orelse", d.clause(n.Else)},
+		)
+	case *Try:
+		handlers := []any{}
+		for _, handler := range n.Handlers {
+			handlers = append(handlers, d.handler(handler))
+		}
+		return d.node(n, "Try",
+			dumpField{"body", d.block(n.Body)},
+			dumpField{"handlers", handlers},
+			dumpField{"orelse", d.clause(n.Else)},
+			dumpField{"finalbody", d.clause(n.Finally)},
+		)
+	case *Raise:
+		return d.node(n, "Raise",
+			dumpField{"exc", d.optional(n.Exc)},
+			dumpField{"cause", d.optional(n.Cause)},
+		)
+	case *With:
+		class := "With"
+		if n.Async {
+			class = "AsyncWith"
+		}
+		items := []any{}
+		for _, item := range n.Items {
+			items = append(items, d.withItem(item))
+		}
+		return d.node(n, class,
+			dumpField{"items", items},
+			dumpField{"body", d.block(n.Body)},
+		)
+	case *Match:
+		cases := []any{}
+		for _, matchCase := range n.Cases {
+			cases = append(cases, d.matchCase(matchCase))
+		}
+		return d.node(n, "Match",
+			dumpField{"subject", d.expr(n.Subject, "Load")},
+			dumpField{"cases", cases},
+		)
+	case *Assign:
+		return d.node(n, "Assign",
+			dumpField{"targets", d.exprs(n.Targets, "Store")},
+			dumpField{"value", d.expr(n.Value, "Load")},
+		)
+	case *AugAssign:
+		return d.node(n, "AugAssign",
+			dumpField{"target", d.expr(n.Target, "Store")},
+			dumpField{"op", dumpOperator(strings.TrimSuffix(n.Op, "="))},
+			dumpField{"value", d.expr(n.Value, "Load")},
+		)
+	case *AnnAssign:
+		// simple es 1 cuando el destino es un nombre sin paréntesis
+		simple := "0"
+		if _, ok := n.Target.(*Identifier); ok {
+			simple = "1"
+		}
+		return d.node(n, "AnnAssign",
+			dumpField{"target", d.expr(n.Target, "Store")},
+			dumpField{"annotation", d.expr(n.Annotation, "Load")},
+			dumpField{"value", d.optional(n.Value)},
+			dumpField{"simple", simple},
+		)
+	case *ExprStmt:
+		return d.node(n, "Expr", dumpField{"value", d.expr(n.Value, "Load")})
+	case *Pass:
+		return d.node(n, "Pass")
+	case *Global:
+		return d.node(n, "Global", dumpField{"names", identifierNames(n.Names)})
+	case *Nonlocal:
+		return d.node(n, "Nonlocal", dumpField{"names", identifierNames(n.Names)})
+	case *Delete:
+		return d.node(n, "Delete", dumpField{"targets", d.exprs(n.Targets, "Del")})
+	case *Assert:
+		return d.node(n, "Assert",
+			dumpField{"test", d.expr(n.Test, "Load")},
+			dumpField{"msg", d.optional(n.Msg)},
+		)
+	}
+	return nil
+}
+
+func (d *dumper) decorators(decorators []*Decorator) []any {
+	result := []any{}
+	for _, decorator := range decorators {
+		result = append(result, d.expr(decorator.Expr, "Load"))
+	}
+	return result
+}
+
+// arguments reparte los parámetros en los campos del nodo arguments. Los
+// valores por omisión de los parámetros posicionales van juntos en
+// defaults y los de los keyword-only en kw_defaults, con None donde falta.
+func (d *dumper) arguments(params []*Parameter) *dumpNode {
+	posOnly, args, kwOnly, kwDefaults, defaults := []any{}, []any{}, []any{}, []any{}, []any{}
+	var vararg, kwarg any
+	for _, param := range params {
+		switch param.Kind {
+		case PositionalOnlyParam:
+			posOnly = append(posOnly, d.arg(param))
+		case RegularParam:
+			args = append(args, d.arg(param))
+		case VarArgsParam:
+			vararg = d.arg(param)
+			continue
+		case KeywordOnlyParam:
+			kwOnly = append(kwOnly, d.arg(param))
+			kwDefaults = append(kwDefaults, d.optional(param.Default))
+			continue
+		case KwArgsParam:
+			kwarg = d.arg(param)
+			continue
+		}
+		if param.Default != nil {
+			defaults = append(defaults, d.expr(param.Default, "Load"))
+		}
+	}
+	return &dumpNode{class: "arguments", fields: []dumpField{
+		{"posonlyargs", posOnly},
+		{"args", args},
+		{"vararg", vararg},
+		{"kwonlyargs", kwOnly},
+		{"kw_defaults", kwDefaults},
+		{"kwarg", kwarg},
+		{"defaults", defaults},
+	}}
+}
+
+func (d *dumper) arg(param *Parameter) *dumpNode {
+	return d.node(param, "arg",
+		dumpField{"arg", pythonString(param.Name)},
+		dumpField{"annotation", d.optional(param.Annotation)},
+	)
+}
+
+func (d *dumper) keyword(keyword *Keyword) *dumpNode {
+	var name any
+	if keyword.Name != "" {
+		name = pythonString(keyword.Name)
+	}
+	return d.node(keyword, "keyword",
+		dumpField{"arg", name},
+		dumpField{"value", d.expr(keyword.Value, "Load")},
+	)
+}
+
+func (d *dumper) handler(handler *ExceptHandler) *dumpNode {
+	var name any
+	if handler.Name != "" {
+		name = pythonString(handler.Name)
+	}
+	return d.node(handler, "ExceptHandler",
+		dumpField{"type", d.optional(handler.Type)},
+		dumpField{"name", name},
+		dumpField{"body", d.block(handler.Body)},
+	)
+}
+
+func (d *dumper) withItem(item *WithItem) *dumpNode {
+	var target any
+	if item.Target != nil {
+		target = d.expr(item.Target, "Store")
+	}
+	return &dumpNode{class: "withitem", fields: []dumpField{
+		{"context_expr", d.expr(item.Context, "Load")},
+		{"optional_vars", target},
+	}}
+}
+
+func (d *dumper) matchCase(matchCase *MatchCase) *dumpNode {
+	return &dumpNode{class: "match_case", fields: []dumpField{
+		{"pattern", d.pattern(matchCase.Pattern)},
+		{"guard", d.optional(matchCase.Guard)},
+		{"body", d.block(matchCase.Body)},
+	}}
+}
+
+func (d *dumper) comprehension(c *Comprehension) *dumpNode {
+	isAsync := "0"
+	if c.Async {
+		isAsync = "1"
+	}
+	return &dumpNode{class: "comprehension", fields: []dumpField{
+		{"target", d.expr(c.Target, "Store")},
+		{"iter", d.expr(c.Iter, "Load")},
+		{"ifs", d.exprs(c.Ifs, "Load")},
+		{"is_async", isAsync},
+	}}
+}
+
+func (d *dumper) comprehensions(generators []*Comprehension) []any {
+	result := []any{}
+	for _, generator := range generators {
+		result = append(result, d.comprehension(generator))
+	}
+	return result
+}
+
+// optional devuelve nil para un campo opcional vacío, que ast.dump omite.
+func (d *dumper) optional(expr Expr) any {
+	if expr == nil {
+		return nil
+	}
+	return d.expr(expr, "Load")
+}
+
+func (d *dumper) exprs(list []Expr, ctx string) []any {
+	result := []any{}
+	for _, expr := range list {
+		result = append(result, d.expr(expr, ctx))
+	}
+	return result
+}
+
+// expr imprime una expresión. ctx es el contexto de los nombres, tuplas,
+// listas, atributos y subíndices: Load al leerlos, Store como destino de
+// una asignación y Del en del.
+func (d *dumper) expr(expr Expr, ctx string) any {
+	context := &dumpNode{class: ctx}
+	switch n := expr.(type) {
+	case *Identifier:
+		return d.node(n, "Name", dumpField{"id", pythonString(n.Name)}, dumpField{"ctx", context})
+	case *Number:
+		return d.constant(n, pythonNumber(n.Value))
+	case *String:
+		return d.constant(n, pythonString(unquote(n.Value)))
+	case *Boolean:
+		if n.Value {
+			return d.constant(n, "True")
+		}
+		return d.constant(n, "False")
+	case *None:
+		return d.constant(n, "None")
+	case *Ellipsis:
+		return d.constant(n, "Ellipsis")
+	case *List:
+		return d.node(n, "List", dumpField{"elts", d.exprs(n.Elts, ctx)}, dumpField{"ctx", context})
+	case *Tuple:
+		return d.node(n, "Tuple", dumpField{"elts", d.exprs(n.Elts, ctx)}, dumpField{"ctx", context})
+	case *Set:
+		return d.node(n, "Set", dumpField{"elts", d.exprs(n.Elts, "Load")})
+	case *Dict:
+		keys := []any{}
+		for _, key := range n.Keys {
+			if key == nil {
+				keys = append(keys, "None")
+				continue
+			}
+			keys = append(keys, d.expr(key, "Load"))
+		}
+		return d.node(n, "Dict", dumpField{"keys", keys}, dumpField{"values", d.exprs(n.Values, "Load")})
+	case *Starred:
+		return d.node(n, "Starred", dumpField{"value", d.expr(n.Value, ctx)}, dumpField{"ctx", context})
+	case *Subscript:
+		return d.node(n, "Subscript",
+			dumpField{"value", d.expr(n.Value, "Load")},
+			dumpField{"slice", d.expr(n.Index, "Load")},
+			dumpField{"ctx", context},
+		)
+	case *Slice:
+		return d.node(n, "Slice",
+			dumpField{"lower", d.optional(n.Lower)},
+			dumpField{"upper", d.optional(n.Upper)},
+			dumpField{"step", d.optional(n.Step)},
+		)
+	case *Attribute:
+		return d.node(n, "Attribute",
+			dumpField{"value", d.expr(n.Value, "Load")},
+			dumpField{"attr", pythonString(n.Attr)},
+			dumpField{"ctx", context},
+		)
+	case *Call:
+		keywords := []any{}
+		for _, keyword := range n.Keywords {
+			keywords = append(keywords, d.keyword(keyword))
+		}
+		return d.node(n, "Call",
+			dumpField{"func", d.expr(n.Func, "Load")},
+			dumpField{"args", d.exprs(n.Args, "Load")},
+			dumpField{"keywords", keywords},
+		)
+	case *UnaryOp:
+		return d.node(n, "UnaryOp",
+			dumpField{"op", dumpOperator("unary" + n.Op)},
+			dumpField{"operand", d.expr(n.Operand, "Load")},
+		)
+	case *BinaryOp:
+		if _, isComparison := comparisonOperators[n.Op]; isComparison {
+			return d.node(n, "Compare",
+				dumpField{"left", d.expr(n.Left, "Load")},
+				dumpField{"ops", []any{dumpOperator(n.Op)}},
+				dumpField{"comparators", []any{d.expr(n.Right, "Load")}},
+			)
+		}
+		return d.node(n, "BinOp",
+			dumpField{"left", d.expr(n.Left, "Load")},
+			dumpField{"op", dumpOperator(n.Op)},
+			dumpField{"right", d.expr(n.Right, "Load")},
+		)
+	case *Compare:
+		ops := []any{}
+		for _, op := range n.Ops {
+			ops = append(ops, dumpOperator(op))
+		}
+		return d.node(n, "Compare",
+			dumpField{"left", d.expr(n.Left, "Load")},
+			dumpField{"ops", ops},
+			dumpField{"comparators", d.exprs(n.Comparators, "Load")},
+		)
+	case *BoolOp:
+		return d.node(n, "BoolOp",
+			dumpField{"op", dumpOperator(n.Op)},
+			dumpField{"values", d.exprs(n.Values, "Load")},
+		)
+	case *IfExp:
+		return d.node(n, "IfExp",
+			dumpField{"test", d.expr(n.Test, "Load")},
+			dumpField{"body", d.expr(n.Body, "Load")},
+			dumpField{"orelse", d.expr(n.Orelse, "Load")},
+		)
+	case *Lambda:
+		return d.node(n, "Lambda",
+			dumpField{"args", d.arguments(n.Params)},
+			dumpField{"body", d.expr(n.Body, "Load")},
+		)
+	case *NamedExpr:
+		return d.node(n, "NamedExpr",
+			dumpField{"target", d.expr(n.Target, "Store")},
+			dumpField{"value", d.expr(n.Value, "Load")},
+		)
+	case *ListComp:
+		return d.node(n, "ListComp",
+			dumpField{"elt", d.expr(n.Elt, "Load")},
+			dumpField{"generators", d.comprehensions(n.Generators)},
+		)
+	case *SetComp:
+		return d.node(n, "SetComp",
+			dumpField{"elt", d.expr(n.Elt, "Load")},
+			dumpField{"generators", d.comprehensions(n.Generators)},
+		)
+	case *GeneratorExp:
+		return d.node(n, "GeneratorExp",
+			dumpField{"elt", d.expr(n.Elt, "Load")},
+			dumpField{"generators", d.comprehensions(n.Generators)},
+		)
+	case *DictComp:
+		return d.node(n, "DictComp",
+			dumpField{"key", d.expr(n.Key, "Load")},
+			dumpField{"value", d.expr(n.Value, "Load")},
+			dumpField{"generators", d.comprehensions(n.Generators)},
+		)
+	case *Await:
+		return d.node(n, "Await", dumpField{"value", d.expr(n.Value, "Load")})
+	}
+	return nil
+}
+
+func (d *dumper) constant(n Node, value string) *dumpNode {
+	return d.node(n, "Constant", dumpField{"value", value})
+}
+
+func (d *dumper) pattern(pattern Pattern) any {
+	switch n := pattern.(type) {
+	case *MatchValue:
+		return d.node(n, "MatchValue", dumpField{"value", d.expr(n.Value, "Load")})
+	case *MatchSingleton:
+		return d.node(n, "MatchSingleton", dumpField{"value", n.Value})
+	case *MatchSequence:
+		return d.node(n, "MatchSequence", dumpField{"patterns", d.patterns(n.Patterns)})
+	case *MatchStar:
+		return d.node(n, "MatchStar", dumpField{"name", optionalName(n.Name)})
+	case *MatchMapping:
+		// Las claves son expresiones en CPython
+		keys := []any{}
+		for _, key := range n.Keys {
+			switch k := key.(type) {
+			case *MatchValue:
+				keys = append(keys, d.expr(k.Value, "Load"))
+			case *MatchSingleton:
+				keys = append(keys, d.constant(k, k.Value))
+			default:
+				keys = append(keys, d.pattern(k))
+			}
+		}
+		return d.node(n, "MatchMapping",
+			dumpField{"keys", keys},
+			dumpField{"patterns", d.patterns(n.Patterns)},
+			dumpField{"rest", optionalName(n.Rest)},
+		)
+	case *MatchClass:
+		attrs, patterns := []any{}, []any{}
+		for _, keyword := range n.Keywords {
+			attrs = append(attrs, pythonString(keyword.Name))
+			patterns = append(patterns, d.pattern(keyword.Pattern))
+		}
+		return d.node(n, "MatchClass",
+			dumpField{"cls", d.expr(n.Cls, "Load")},
+			dumpField{"patterns", d.patterns(n.Patterns)},
+			dumpField{"kwd_attrs", attrs},
+			dumpField{"kwd_patterns", patterns},
+		)
+	case *MatchAs:
+		var inner any
+		if n.Pattern != nil {
+			inner = d.pattern(n.Pattern)
+		}
+		return d.node(n, "MatchAs", dumpField{"pattern", inner}, dumpField{"name", optionalName(n.Name)})
+	case *MatchOr:
+		return d.node(n, "MatchOr", dumpField{"patterns", d.patterns(n.Patterns)})
+	}
+	return nil
+}
+
+func (d *dumper) patterns(list []Pattern) []any {
+	result := []any{}
+	for _, pattern := range list {
+		result = append(result, d.pattern(pattern))
+	}
+	return result
+}
+
+var comparisonOperators = map[string]string{
+	"==": "Eq", "!=": "NotEq", "<": "Lt", "<=": "LtE", ">": "Gt", ">=": "GtE",
+	"is": "Is", "is not": "IsNot", "in": "In", "not in": "NotIn",
+}
+
+var dumpOperators = map[string]string{
+	"+": "Add", "-": "Sub", "*": "Mult", "@": "MatMult", "/": "Div", "%": "Mod",
+	"**": "Pow", "<<": "LShift", ">>": "RShift", "|": "BitOr", "^": "BitXor",
+	"&": "BitAnd", "//": "FloorDiv",
+	"and": "And", "or": "Or",
+	"unary+": "UAdd", "unary-": "USub", "unary~": "Invert", "unarynot": "Not",
+}
+
+// dumpOperator devuelve el nodo del operador; los unarios llevan el
+// prefijo "unary" para distinguir -x de a - b.
+func dumpOperator(op string) *dumpNode {
+	if class, ok := comparisonOperators[op]; ok {
+		return &dumpNode{class: class}
+	}
+	return &dumpNode{class: dumpOperators[op]}
+}
+
+func identifierNames(names []*Identifier) []any {
+	result := []any{}
+	for _, name := range names {
+		result = append(result, pythonString(name.Name))
+	}
+	return result
+}
+
+func optionalName(name string) any {
+	if name == "" {
+		return nil
+	}
+	return pythonString(name)
+}
+
+// pythonNumber devuelve el repr del valor de un literal numérico: los
+// enteros sin ceros a la izquierda y los flotantes en la forma más corta.
+func pythonNumber(literal string) string {
+	if !strings.ContainsAny(literal, ".") {
+		if value, ok := new(big.Int).SetString(literal, 10); ok {
+			return value.String()
+		}
+		return literal
+	}
+	value, err := strconv.ParseFloat(literal, 64)
+	if err != nil && !math.IsInf(value, 0) {
+		return literal
+	}
+	return pythonFloat(value)
+}
+
+// pythonFloat sigue a repr(float): notación fija con al menos un decimal si
+// el exponente está entre -4 y 15, y científica fuera de ese rango.
+func pythonFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "inf"
+	}
+	scientific := strconv.FormatFloat(value, 'e', -1, 64)
+	mantissa, exponentText, _ := strings.Cut(scientific, "e")
+	exponent, _ := strconv.Atoi(exponentText)
+	if exponent < -4 || exponent >= 16 {
+		return fmt.Sprintf("%se%+03d", mantissa, exponent)
+	}
+	fixed := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(fixed, ".") {
+		fixed += ".0"
+	}
+	return fixed
+}
+
+// unquote interpreta las secuencias de escape de un literal de cadena con
+// sus comillas. Las secuencias que Python no reconoce se conservan tal
+// cual, con la barra invertida.
+func unquote(literal string) string {
+	if len(literal) >= 2 {
+		literal = literal[1 : len(literal)-1]
+	}
+	var sb strings.Builder
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' || i+1 == len(literal) {
+			sb.WriteByte(literal[i])
+			continue
+		}
+		i++
+		switch c := literal[i]; c {
+		case '\\', '\'', '"':
+			sb.WriteByte(c)
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			code, err := strconv.ParseUint(literal[i+1:min(i+1+digits, len(literal))], 16, 32)
+			if err != nil || i+digits >= len(literal) {
+				sb.WriteByte('\\')
+				sb.WriteByte(c)
+				continue
+			}
+			sb.WriteRune(rune(code))
+			i += digits
+		default:
+			if c >= '0' && c <= '7' {
+				end := i + 1
+				for end < len(literal) && end < i+3 && literal[end] >= '0' && literal[end] <= '7' {
+					end++
+				}
+				code, _ := strconv.ParseUint(literal[i:end], 8, 32)
+				sb.WriteRune(rune(code))
+				i = end - 1
+				continue
+			}
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// pythonString devuelve el repr de una cadena: comillas simples salvo que
+// contenga comillas simples y no dobles, y escapes para los caracteres no
+// imprimibles.
+func pythonString(s string) string {
+	quote := byte('\'')
+	if strings.Contains(s, "'") && !strings.Contains(s, "\"") {
+		quote = '"'
+	}
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for _, r := range s {
+		switch {
+		case r == rune(quote) || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < ' ' || r == 0x7f || (r >= 0x80 && r <= 0xff && !unicode.IsPrint(r)):
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case !unicode.IsPrint(r) && r <= 0xffff:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&sb, `\U%08x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	
+	"examencorte2/src/lexer"
+)
+
+// Los archivos .golden de testdata/dump son la salida de CPython 3.11 para
+// cada programa, generada con:
+//
+//	python3 -c "import ast; print(ast.dump(ast.parse(open('x.py').read())))" > x.golden
+//	python3 -c "import ast; print(ast.dump(ast.parse(open('x.py').read()), indent=2))" > x.indent.golden
+//	python3 -c "import ast; print(ast.dump(ast.parse(open('x.py').read()), indent=0))" > x.indent0.golden
+//	python3 -c "import ast; print(ast.dump(ast.parse(open('x.py').read()), include_attributes=True))" > x.attributes.golden
+func TestDumpGolden(t *testing.T) {
+	sources, err := filepath.Glob(filepath.Join("testdata", "dump", "*.py"))
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("no se encontraron programas en testdata/dump: %v", err)
+	}
+	
+	two, zero := 2, 0
+	modes := map[string]DumpOptions{
+		".golden":            {},
+		".indent.golden":     {Indent: &two},
+		".indent0.golden":    {Indent: &zero},
+		".attributes.golden": {Attributes: true},
+	}
+	for _, source := range sources {
+		code, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := Analyze(lexer.Analyze(string(code)).Tokens)
+		if len(result.Errors) > 0 {
+			t.Errorf("%s: errores de sintaxis: %v", source, result.Errors)
+			continue
+		}
+		
+		for suffix, options := range modes {
+			golden := strings.TrimSuffix(source, ".py") + suffix
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Dump(result.Module, options) + "\n"; got != string(want) {
+				t.Errorf("%s no coincide con %s:\n%s", source, golden, got)
+			}
+		}
+	}
+}
+
+// TestDumpWithoutTree verifica que las salidas de un análisis fallido de los
+// parsers dirigidos por tabla, que no arman el árbol, queden vacías.
+func TestDumpWithoutTree(t *testing.T) {
+	tokens := lexer.Analyze("x = 1 +\n").Tokens
+	for name, analyze := range map[string]func([]lexer.Token) SyntaxResult{
+		"AnalyzeLL1":  AnalyzeLL1,
+		"AnalyzeLALR": AnalyzeLALR,
+	} {
+		result := analyze(tokens)
+		if result.Success || result.Module != nil {
+			t.Fatalf("%s: se esperaba un error sin árbol", name)
+		}
+		if got := Dump(result.Module, DumpOptions{}); got != "" {
+			t.Errorf("%s: Dump = %q", name, got)
+		}
+		if got := Unparse(result.Module); got != "" {
+			t.Errorf("%s: Unparse = %q", name, got)
+		}
+		ToDOT(result.AST)
+		ToMermaid(result.AST)
+	}
+}
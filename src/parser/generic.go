@@ -0,0 +1,398 @@
+package parser
+
+import "strings"
+
+// ASTNode es la forma genérica del árbol que se envía al frontend: cada nodo
+// tiene un tipo, un valor opcional, la región del código que ocupa y sus
+// hijos en orden.
+type ASTNode struct {
+	Type     string     `json:"type"`
+	Value    string     `json:"value,omitempty"`
+	Line     int        `json:"line"`
+	Span     Span       `json:"span"`
+	Children []*ASTNode `json:"children,omitempty"`
+
+	// Anotación de tipo de un parámetro, del retorno de una función o de
+	// una asignación anotada (AnnAssign)
+	Annotation *ASTNode `json:"annotation,omitempty"`
+}
+
+// ToASTNode convierte un nodo tipado a la forma genérica. Los campos
+// opcionales vacíos se omiten, salvo en Slice, que siempre tiene tres hijos
+// y usa nodos Empty para las partes omitidas.
+func ToASTNode(node Node) *ASTNode {
+	g := &ASTNode{Line: node.Pos().Line, Span: node.Pos().Span()}
+
+	switch n := node.(type) {
+	case *Module:
+		g.Type = "Program"
+		g.Children = stmts(n.Body)
+	case *Block:
+		g.Type = "Block"
+		g.Children = stmts(n.Body)
+	case *BadStmt:
+		g.Type = "Error"
+		g.Value = n.Message
+		for _, block := range n.Blocks {
+			g.Children = append(g.Children, ToASTNode(block))
+		}
+
+	case *FunctionDef:
+		g.Type = "FunctionDef"
+		if n.Async {
+			g.Type = "AsyncFunctionDef"
+		}
+		g.Value = n.Name
+		g.Children = decorators(n.Decorators)
+		for _, param := range n.Params {
+			g.Children = append(g.Children, ToASTNode(param))
+		}
+		g.Children = append(g.Children, ToASTNode(n.Body))
+		g.Annotation = optional(n.Returns)
+	case *Decorator:
+		g.Type = "Decorator"
+		g.Children = []*ASTNode{ToASTNode(n.Expr)}
+	case *Parameter:
+		g.Type = n.Kind.String()
+		g.Value = n.Name
+		g.Children = optionals(n.Default)
+		g.Annotation = optional(n.Annotation)
+	case *ClassDef:
+		g.Type = "ClassDef"
+		g.Value = n.Name
+		g.Children = append(decorators(n.Decorators), exprs(n.Bases)...)
+		g.Children = append(g.Children, keywords(n.Keywords)...)
+		g.Children = append(g.Children, ToASTNode(n.Body))
+	case *If:
+		g.Type = "IfStatement"
+		g.Children = []*ASTNode{ToASTNode(n.Test), ToASTNode(n.Body)}
+	case *For:
+		g.Type = "For"
+		if n.Async {
+			g.Type = "AsyncFor"
+		}
+		g.Children = []*ASTNode{ToASTNode(n.Target), ToASTNode(n.Iter), ToASTNode(n.Body)}
+		g.Children = append(g.Children, clause("Else", n.Else)...)
+	case *Try:
+		g.Type = "Try"
+		g.Children = []*ASTNode{ToASTNode(n.Body)}
+		for _, handler := range n.Handlers {
+			g.Children = append(g.Children, ToASTNode(handler))
+		}
+		g.Children = append(g.Children, clause("Else", n.Else)...)
+		g.Children = append(g.Children, clause("Finally", n.Finally)...)
+	case *ExceptHandler:
+		g.Type = "ExceptHandler"
+		g.Value = n.Name
+		g.Children = append(optionals(n.Type), ToASTNode(n.Body))
+	case *Raise:
+		g.Type = "Raise"
+		g.Children = optionals(n.Exc, n.Cause)
+	case *With:
+		g.Type = "With"
+		if n.Async {
+			g.Type = "AsyncWith"
+		}
+		for _, item := range n.Items {
+			g.Children = append(g.Children, ToASTNode(item))
+		}
+		g.Children = append(g.Children, ToASTNode(n.Body))
+	case *WithItem:
+		g.Type = "WithItem"
+		g.Children = optionals(n.Context, n.Target)
+	case *Match:
+		g.Type = "Match"
+		g.Children = []*ASTNode{ToASTNode(n.Subject)}
+		for _, matchCase := range n.Cases {
+			g.Children = append(g.Children, ToASTNode(matchCase))
+		}
+	case *MatchCase:
+		g.Type = "MatchCase"
+		g.Children = append(optionals(n.Pattern, n.Guard), ToASTNode(n.Body))
+	case *Assign:
+		g.Type = "Assign"
+		g.Children = append(exprs(n.Targets), ToASTNode(n.Value))
+	case *AugAssign:
+		g.Type = "AugAssign"
+		g.Value = n.Op
+		g.Children = []*ASTNode{ToASTNode(n.Target), ToASTNode(n.Value)}
+	case *AnnAssign:
+		g.Type = "AnnAssign"
+		g.Children = optionals(n.Target, n.Value)
+		g.Annotation = ToASTNode(n.Annotation)
+	case *ExprStmt:
+		g.Type = "ExpressionStatement"
+		g.Children = []*ASTNode{ToASTNode(n.Value)}
+	case *Pass:
+		g.Type = "Pass"
+	case *Global:
+		g.Type = "Global"
+		g.Children = identifiers(n.Names)
+	case *Nonlocal:
+		g.Type = "Nonlocal"
+		g.Children = identifiers(n.Names)
+	case *Delete:
+		g.Type = "Delete"
+		g.Children = exprs(n.Targets)
+	case *Assert:
+		g.Type = "Assert"
+		g.Children = optionals(n.Test, n.Msg)
+
+	case *Identifier:
+		g.Type = "Identifier"
+		g.Value = n.Name
+	case *Number:
+		g.Type = "Number"
+		g.Value = n.Value
+	case *String:
+		g.Type = "String"
+		g.Value = n.Value
+	case *Boolean:
+		g.Type = "Boolean"
+		g.Value = "False"
+		if n.Value {
+			g.Value = "True"
+		}
+	case *None:
+		g.Type = "None"
+		g.Value = "None"
+	case *Ellipsis:
+		g.Type = "Ellipsis"
+		g.Value = "..."
+	case *List:
+		g.Type = "List"
+		g.Children = exprs(n.Elts)
+	case *Tuple:
+		g.Type = "Tuple"
+		g.Children = exprs(n.Elts)
+	case *Set:
+		g.Type = "Set"
+		g.Children = exprs(n.Elts)
+	case *Dict:
+		g.Type = "Dict"
+		for i, key := range n.Keys {
+			value := ToASTNode(n.Values[i])
+			if key == nil {
+				g.Children = append(g.Children, &ASTNode{
+					Type:     "DoubleStarred",
+					Line:     value.Line,
+					Span:     value.Span,
+					Children: []*ASTNode{value},
+				})
+				continue
+			}
+			g.Children = append(g.Children, &ASTNode{
+				Type:     "KeyValue",
+				Line:     key.Pos().Line,
+				Span:     through(key.Pos(), n.Values[i].Pos()).Span(),
+				Children: []*ASTNode{ToASTNode(key), value},
+			})
+		}
+	case *Starred:
+		g.Type = "Starred"
+		g.Children = []*ASTNode{ToASTNode(n.Value)}
+	case *Subscript:
+		g.Type = "Subscript"
+		g.Children = []*ASTNode{ToASTNode(n.Value), ToASTNode(n.Index)}
+	case *Slice:
+		g.Type = "Slice"
+		for _, part := range []Expr{n.Lower, n.Upper, n.Step} {
+			if part == nil {
+				g.Children = append(g.Children, &ASTNode{Type: "Empty", Line: g.Line, Span: g.Span})
+			} else {
+				g.Children = append(g.Children, ToASTNode(part))
+			}
+		}
+	case *Attribute:
+		g.Type = "Attribute"
+		g.Value = n.Attr
+		g.Children = []*ASTNode{ToASTNode(n.Value)}
+	case *Call:
+		g.Type = "Call"
+		g.Children = append([]*ASTNode{ToASTNode(n.Func)}, exprs(n.Args)...)
+		g.Children = append(g.Children, keywords(n.Keywords)...)
+	case *Keyword:
+		g.Type = "Keyword"
+		if n.Name == "" {
+			g.Type = "DoubleStarred"
+		}
+		g.Value = n.Name
+		g.Children = []*ASTNode{ToASTNode(n.Value)}
+	case *UnaryOp:
+		g.Type = "UnaryOp"
+		g.Value = n.Op
+		g.Children = []*ASTNode{ToASTNode(n.Operand)}
+	case *BinaryOp:
+		g.Type = "BinaryOp"
+		g.Value = n.Op
+		g.Children = []*ASTNode{ToASTNode(n.Left), ToASTNode(n.Right)}
+	case *Compare:
+		g.Type = "Compare"
+		g.Value = strings.Join(n.Ops, ",")
+		g.Children = append([]*ASTNode{ToASTNode(n.Left)}, exprs(n.Comparators)...)
+	case *BoolOp:
+		g.Type = "BoolOp"
+		g.Value = n.Op
+		g.Children = exprs(n.Values)
+	case *IfExp:
+		g.Type = "IfExp"
+		g.Children = []*ASTNode{ToASTNode(n.Test), ToASTNode(n.Body), ToASTNode(n.Orelse)}
+	case *Lambda:
+		g.Type = "Lambda"
+		for _, param := range n.Params {
+			g.Children = append(g.Children, ToASTNode(param))
+		}
+		g.Children = append(g.Children, ToASTNode(n.Body))
+	case *NamedExpr:
+		g.Type = "NamedExpr"
+		g.Children = []*ASTNode{ToASTNode(n.Target), ToASTNode(n.Value)}
+	case *ListComp:
+		g.Type = "ListComp"
+		g.Children = append([]*ASTNode{ToASTNode(n.Elt)}, comprehensions(n.Generators)...)
+	case *SetComp:
+		g.Type = "SetComp"
+		g.Children = append([]*ASTNode{ToASTNode(n.Elt)}, comprehensions(n.Generators)...)
+	case *GeneratorExp:
+		g.Type = "GeneratorExp"
+		g.Children = append([]*ASTNode{ToASTNode(n.Elt)}, comprehensions(n.Generators)...)
+	case *DictComp:
+		g.Type = "DictComp"
+		g.Children = append([]*ASTNode{ToASTNode(n.Key), ToASTNode(n.Value)}, comprehensions(n.Generators)...)
+	case *Comprehension:
+		g.Type = "Comprehension"
+		if n.Async {
+			g.Value = "async"
+		}
+		g.Children = append([]*ASTNode{ToASTNode(n.Target), ToASTNode(n.Iter)}, exprs(n.Ifs)...)
+	case *Await:
+		g.Type = "Await"
+		g.Children = []*ASTNode{ToASTNode(n.Value)}
+
+	case *MatchValue:
+		g.Type = "MatchValue"
+		g.Children = []*ASTNode{ToASTNode(n.Value)}
+	case *MatchSingleton:
+		g.Type = "MatchSingleton"
+		g.Value = n.Value
+	case *MatchSequence:
+		g.Type = "MatchSequence"
+		g.Children = patterns(n.Patterns)
+	case *MatchStar:
+		g.Type = "MatchStar"
+		g.Value = n.Name
+	case *MatchMapping:
+		g.Type = "MatchMapping"
+		g.Value = n.Rest
+		for i, key := range n.Keys {
+			g.Children = append(g.Children, &ASTNode{
+				Type:     "MatchKeyValue",
+				Line:     key.Pos().Line,
+				Span:     through(key.Pos(), n.Patterns[i].Pos()).Span(),
+				Children: []*ASTNode{ToASTNode(key), ToASTNode(n.Patterns[i])},
+			})
+		}
+	case *MatchClass:
+		g.Type = "MatchClass"
+		g.Children = append([]*ASTNode{ToASTNode(n.Cls)}, patterns(n.Patterns)...)
+		for _, keyword := range n.Keywords {
+			g.Children = append(g.Children, ToASTNode(keyword))
+		}
+	case *MatchKeyword:
+		g.Type = "MatchKeyword"
+		g.Value = n.Name
+		g.Children = []*ASTNode{ToASTNode(n.Pattern)}
+	case *MatchAs:
+		g.Type = "MatchAs"
+		g.Value = n.Name
+		g.Children = optionals(n.Pattern)
+	case *MatchOr:
+		g.Type = "MatchOr"
+		g.Children = patterns(n.Patterns)
+	}
+
+	return g
+}
+
+func stmts(body []Stmt) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, stmt := range body {
+		nodes = append(nodes, ToASTNode(stmt))
+	}
+	return nodes
+}
+
+func exprs(list []Expr) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, expr := range list {
+		nodes = append(nodes, ToASTNode(expr))
+	}
+	return nodes
+}
+
+func patterns(list []Pattern) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, pattern := range list {
+		nodes = append(nodes, ToASTNode(pattern))
+	}
+	return nodes
+}
+
+func identifiers(names []*Identifier) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, name := range names {
+		nodes = append(nodes, ToASTNode(name))
+	}
+	return nodes
+}
+
+func decorators(list []*Decorator) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, decorator := range list {
+		nodes = append(nodes, ToASTNode(decorator))
+	}
+	return nodes
+}
+
+func keywords(list []*Keyword) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, keyword := range list {
+		nodes = append(nodes, ToASTNode(keyword))
+	}
+	return nodes
+}
+
+func comprehensions(list []*Comprehension) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, generator := range list {
+		nodes = append(nodes, ToASTNode(generator))
+	}
+	return nodes
+}
+
+// clause convierte una cláusula else o finally en un nodo con el bloque como
+// único hijo.
+func clause(kind string, c *Clause) []*ASTNode {
+	if c == nil {
+		return nil
+	}
+	return []*ASTNode{{Type: kind, Line: c.Line, Span: c.Span(), Children: []*ASTNode{ToASTNode(c.Body)}}}
+}
+
+// optional convierte un nodo que puede faltar.
+func optional(node Node) *ASTNode {
+	if node == nil {
+		return nil
+	}
+	return ToASTNode(node)
+}
+
+// optionals convierte los nodos presentes, en orden.
+func optionals(list ...Node) []*ASTNode {
+	nodes := []*ASTNode{}
+	for _, node := range list {
+		if node != nil {
+			nodes = append(nodes, ToASTNode(node))
+		}
+	}
+	return nodes
+}
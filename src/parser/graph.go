@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT exporta el árbol genérico como un grafo de Graphviz. Cada nodo
+// muestra su tipo, su valor y su línea; las anotaciones de tipo cuelgan de
+// su nodo con una arista punteada.
+func ToDOT(root *ASTNode) string {
+	var sb strings.Builder
+	sb.WriteString("digraph AST {\n")
+	sb.WriteString("\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	walkGraph(root,
+		func(id int, node *ASTNode) {
+			fmt.Fprintf(&sb, "\tn%d [label=\"%s\"];\n", id, dotEscape(graphLabel(node)))
+		},
+		func(from, to int, annotation bool) {
+			if annotation {
+				fmt.Fprintf(&sb, "\tn%d -> n%d [style=dashed, label=\"anotación\"];\n", from, to)
+				return
+			}
+			fmt.Fprintf(&sb, "\tn%d -> n%d;\n", from, to)
+		},
+	)
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// ToMermaid exporta el árbol genérico como un diagrama de flujo de Mermaid
+// con la misma información que ToDOT.
+func ToMermaid(root *ASTNode) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	walkGraph(root,
+		func(id int, node *ASTNode) {
+			fmt.Fprintf(&sb, "    n%d[\"%s\"]\n", id, mermaidEscape(graphLabel(node)))
+		},
+		func(from, to int, annotation bool) {
+			if annotation {
+				fmt.Fprintf(&sb, "    n%d -.->|anotación| n%d\n", from, to)
+				return
+			}
+			fmt.Fprintf(&sb, "    n%d --> n%d\n", from, to)
+		},
+	)
+	return sb.String()
+}
+
+// walkGraph numera los nodos en preorden y llama a node por cada uno y a
+// edge por cada arista, después de declarar el nodo destino.
+func walkGraph(root *ASTNode, node func(id int, n *ASTNode), edge func(from, to int, annotation bool)) {
+	next := 0
+	var visit func(n *ASTNode) int
+	visit = func(n *ASTNode) int {
+		id := next
+		next++
+		node(id, n)
+		if n.Annotation != nil {
+			edge(id, visit(n.Annotation), true)
+		}
+		for _, child := range n.Children {
+			edge(id, visit(child), false)
+		}
+		return id
+	}
+	if root != nil {
+		visit(root)
+	}
+}
+
+func graphLabel(node *ASTNode) string {
+	parts := []string{node.Type}
+	if node.Value != "" {
+		parts = append(parts, node.Value)
+	}
+	parts = append(parts, fmt.Sprintf("línea %d", node.Line))
+	return strings.Join(parts, "\n")
+}
+
+func dotEscape(label string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(label)
+}
+
+// mermaidEscape usa entidades para los caracteres que Mermaid interpreta
+// dentro de una etiqueta entre comillas.
+func mermaidEscape(label string) string {
+	return strings.NewReplacer(
+		"#", "#35;", `"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>",
+	).Replace(label)
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"testing"
+	
+	"examencorte2/src/lexer"
+)
+
+// TestGraphEscapes verifica que las comillas, las barras y el '#' de los
+// literales no rompan las etiquetas de Graphviz ni de Mermaid.
+func TestGraphEscapes(t *testing.T) {
+	result := Analyze(lexer.Analyze("x: int = \"a\\\"b\"\ny = '#x'\n").Tokens)
+	if !result.Success {
+		t.Fatal(result.Errors)
+	}
+	
+	dot := `digraph AST {
+	node [shape=box, fontname="Helvetica"];
+	n0 [label="Program\nlínea 1"];
+	n1 [label="AnnAssign\nlínea 1"];
+	n2 [label="Identifier\nint\nlínea 1"];
+	n1 -> n2 [style=dashed, label="anotación"];
+	n3 [label="Identifier\nx\nlínea 1"];
+	n1 -> n3;
+	n4 [label="String\n\"a\\\"b\"\nlínea 1"];
+	n1 -> n4;
+	n0 -> n1;
+	n5 [label="Assign\nlínea 2"];
+	n6 [label="Identifier\ny\nlínea 2"];
+	n5 -> n6;
+	n7 [label="String\n'#x'\nlínea 2"];
+	n5 -> n7;
+	n0 -> n5;
+}
+`
+	if got := ToDOT(result.AST); got != dot {
+		t.Errorf("ToDOT:\n%s", got)
+	}
+	
+	mermaid := `flowchart TD
+    n0["Program<br/>línea 1"]
+    n1["AnnAssign<br/>línea 1"]
+    n2["Identifier<br/>int<br/>línea 1"]
+    n1 -.->|anotación| n2
+    n3["Identifier<br/>x<br/>línea 1"]
+    n1 --> n3
+    n4["String<br/>#quot;a\#quot;b#quot;<br/>línea 1"]
+    n1 --> n4
+    n0 --> n1
+    n5["Assign<br/>línea 2"]
+    n6["Identifier<br/>y<br/>línea 2"]
+    n5 --> n6
+    n7["String<br/>'#35;x'<br/>línea 2"]
+    n5 --> n7
+    n0 --> n5
+`
+	if got := ToMermaid(result.AST); got != mermaid {
+		t.Errorf("ToMermaid:\n%s", got)
+	}
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	
+	"examencorte2/src/gramatica"
+	"examencorte2/src/lexer"
+)
+
+// Tipos de paso de la traza del parser LALR(1)
+const (
+	StepShift  = "shift"
+	StepReduce = "reduce"
+)
+
+// AnalyzeLALR analiza los tokens de abajo hacia arriba con las tablas
+// LALR(1) de la gramática de src/gramatica. Como AnalyzeLL1, produce para
+// un programa válido el mismo árbol que Analyze junto con la traza de
+// desplazamientos y reducciones y el árbol de derivación, y se detiene en
+// el primer error.
+func AnalyzeLALR(tokens []lexer.Token) SyntaxResult {
+	filteredTokens, layoutErrors := layoutTokens(tokens)
+	
+	lr := &shiftReduce{
+		Parser:  &Parser{tokens: filteredTokens, errors: layoutErrors},
+		grammar: gramatica.Python(),
+	}
+	lr.table = lr.grammar.LALR()
+	lr.input = grammarInput(lr.Parser)
+	root := lr.parse()
+	
+	result := SyntaxResult{
+		Errors:  lr.errors,
+		Success: len(lr.errors) == 0,
+		Trace:   &lr.trace,
+	}
+	if root != nil {
+		builder := newTreeBuilder(lr.grammar)
+		module := builder.module(root)
+		result.Errors = append(result.Errors, builder.errors...)
+		result.Success = len(result.Errors) == 0
+		finishGrammarTree(root)
+		result.ParseTree = root
+		result.Derivation = LeftmostDerivation(root)
+		result.AST = ToASTNode(module)
+		result.Unparsed = Unparse(module)
+		result.Module = module
+	}
+	return result
+}
+
+// shiftReduce es el parser LALR(1). Como predictive, usa el Parser del
+// descenso recursivo solo por sus tokens, su posición y sus diagnósticos.
+type shiftReduce struct {
+	*Parser
+	grammar *gramatica.Grammar
+	table   *gramatica.LALRTable
+	input   []string
+	trace   ParseTrace
+}
+
+// lrEntry es un estado de la pila con el símbolo por el que se llegó a él,
+// su nodo del árbol de derivación y la posición en la entrada donde empieza.
+type lrEntry struct {
+	state    int
+	node     *ParseTree
+	position int
+}
+
+// parse desplaza y reduce hasta aceptar o encontrar un error. Devuelve el
+// árbol de derivación, con los no terminales auxiliares de la gramática, o
+// nil si hubo un error.
+func (lr *shiftReduce) parse() *ParseTree {
+	lr.trace.Input = lr.input
+	stack := []lrEntry{{state: 0}}
+	
+	for {
+		top := stack[len(stack)-1]
+		lookahead := lr.input[lr.current]
+		
+		action, ok := lr.table.Action(top.state, lookahead)
+		if !ok {
+			lr.fail(stack, top.state, fmt.Sprintf("No se esperaba %s", lookahead))
+			return nil
+		}
+		if actions := lr.table.Actions(top.state, lookahead); len(actions) > 1 {
+			action = lr.resolve(stack, actions)
+		}
+		
+		switch action.Kind {
+		case gramatica.Accept:
+			lr.step(stack, StepAccept, "acepta")
+			return stack[len(stack)-1].node
+			
+		case gramatica.Shift:
+			lr.step(stack, StepShift, lr.table.Describe(action))
+			position := lr.current
+			token := lr.advance()
+			stack = append(stack, lrEntry{
+				state:    action.Target,
+				position: position,
+				node: &ParseTree{
+					Symbol:   lookahead,
+					Value:    token.Value,
+					Line:     token.Line,
+					terminal: true,
+					position: tokenPosition(token),
+				},
+			})
+			
+		case gramatica.Reduce:
+			lr.step(stack, StepReduce, lr.table.Describe(action))
+			production := lr.grammar.Productions[action.Target]
+			node := &ParseTree{Symbol: production.Head, Production: production.String()}
+			
+			// Una producción ε empieza donde está la entrada
+			position := lr.current
+			popped := stack[len(stack)-len(production.Body):]
+			if len(popped) > 0 {
+				position = popped[0].position
+			}
+			for _, entry := range popped {
+				node.Children = append(node.Children, entry.node)
+			}
+			stack = stack[:len(stack)-len(production.Body)]
+			
+			target, _ := lr.table.Goto(stack[len(stack)-1].state, production.Head)
+			stack = append(stack, lrEntry{state: target, node: node, position: position})
+		}
+	}
+}
+
+// resolve elige entre las acciones de una celda con conflicto. El único de
+// la gramática es el de "with (a, b):", donde al llegar a ',' o ')' no se
+// sabe si los paréntesis agrupan los elementos del with o forman una
+// expresión. Como en el descenso recursivo, los agrupan solo si después
+// del ')' viene ':'; en otro caso gana la última acción.
+func (lr *shiftReduce) resolve(stack []lrEntry, actions []gramatica.Action) gramatica.Action {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].node.Symbol != "'('" {
+			continue
+		}
+		current := lr.current
+		lr.current = stack[i].position
+		grouped := lr.afterGroup().Value == ":"
+		lr.current = current
+		if grouped {
+			return actions[0]
+		}
+		break
+	}
+	return actions[len(actions)-1]
+}
+
+// step anota el paso con la pila como en los libros de texto: los estados
+// intercalados con los símbolos.
+func (lr *shiftReduce) step(stack []lrEntry, kind, action string) {
+	symbols := []string{strconv.Itoa(stack[0].state)}
+	for _, entry := range stack[1:] {
+		symbols = append(symbols, entry.node.Symbol, strconv.Itoa(entry.state))
+	}
+	lr.trace.record(symbols, lr.current, kind, action)
+}
+
+func (lr *shiftReduce) fail(stack []lrEntry, state int, message string) {
+	lr.step(stack, StepError, message)
+	expected := lr.table.Expected(state)
+	names := make([]string, len(expected))
+	for i, terminal := range expected {
+		names[i] = strings.Trim(terminal, "'")
+	}
+	lr.report(Diagnostic{
+		Code:     ErrExpectedToken,
+		Message:  message,
+		Expected: strings.Join(names, " | "),
+	})
+}
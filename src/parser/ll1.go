@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	
+	"examencorte2/src/gramatica"
+	"examencorte2/src/lexer"
+)
+
+// ParseTrace es la traza de un parser dirigido por tabla: la entrada como
+// terminales de la gramática, terminada en $, y cada paso con la pila y la
+// posición de la entrada antes de la acción.
+type ParseTrace struct {
+	Input []string    `json:"input"`
+	Steps []ParseStep `json:"steps"`
+	
+	// Pila del último paso, para anotar el siguiente
+	stack []string
+}
+
+// ParseStep es un paso de la traza. La pila, del fondo al tope, es la del
+// paso anterior sin sus últimos Pop símbolos y con Push agregados; copiarla
+// entera en cada paso haría crecer la traza con el cuadrado del programa.
+// Position es el índice en Input del próximo terminal.
+type ParseStep struct {
+	Pop      int      `json:"pop"`
+	Push     []string `json:"push,omitempty"`
+	Position int      `json:"position"`
+	Kind     string   `json:"kind"`
+	Action   string   `json:"action"`
+}
+
+// Tipos de paso de la traza
+const (
+	StepExpand = "expand"
+	StepMatch  = "match"
+	StepAccept = "accept"
+	StepError  = "error"
+)
+
+// AnalyzeLL1 analiza los tokens con un parser predictivo que sigue la tabla
+// LL(1) de la gramática de src/gramatica en lugar del descenso recursivo.
+// Para un programa válido produce el mismo árbol que Analyze, además de la
+// traza de la pila y del árbol de derivación sobre las producciones de la
+// gramática, con sus no terminales auxiliares. Se detiene en el primer
+// error y no arma el árbol; las restricciones que la gramática no expresa,
+// como qué puede recibir una asignación, las verifica treeBuilder con las
+// mismas reglas que Analyze.
+func AnalyzeLL1(tokens []lexer.Token) SyntaxResult {
+	filteredTokens, layoutErrors := layoutTokens(tokens)
+	
+	ll := &predictive{
+		Parser:  &Parser{tokens: filteredTokens, errors: layoutErrors},
+		grammar: gramatica.Python(),
+	}
+	ll.input = grammarInput(ll.Parser)
+	root := ll.parse()
+	
+	result := SyntaxResult{
+		Errors:  ll.errors,
+		Success: len(ll.errors) == 0,
+		Trace:   &ll.trace,
+	}
+	if root != nil {
+		builder := newTreeBuilder(ll.grammar)
+		module := builder.module(root)
+		result.Errors = append(result.Errors, builder.errors...)
+		result.Success = len(result.Errors) == 0
+		finishGrammarTree(root)
+		result.ParseTree = root
+		result.Derivation = LeftmostDerivation(root)
+		result.AST = ToASTNode(module)
+		result.Unparsed = Unparse(module)
+		result.Module = module
+	}
+	return result
+}
+
+// predictive es el parser LL(1). Usa el Parser del descenso recursivo solo
+// por sus tokens, su posición y sus diagnósticos.
+type predictive struct {
+	*Parser
+	grammar *gramatica.Grammar
+	input   []string
+	trace   ParseTrace
+}
+
+// llEntry es un símbolo de la pila junto con el nodo del árbol de
+// derivación que le corresponde.
+type llEntry struct {
+	symbol string
+	node   *ParseTree
+}
+
+// parse aplica la tabla hasta aceptar o encontrar un error. Devuelve el
+// árbol de derivación, con los no terminales auxiliares de la gramática, o
+// nil si hubo un error.
+func (ll *predictive) parse() *ParseTree {
+	ll.trace.Input = ll.input
+	root := &ParseTree{Symbol: ll.grammar.Start}
+	stack := []llEntry{{symbol: gramatica.EndMarker}, {symbol: root.Symbol, node: root}}
+	
+	for {
+		top := stack[len(stack)-1]
+		lookahead := ll.input[ll.current]
+		
+		switch {
+		case top.symbol == gramatica.EndMarker:
+			if lookahead != gramatica.EndMarker {
+				ll.fail(stack, []string{gramatica.EndMarker}, "Se esperaba el fin del código")
+				return nil
+			}
+			ll.step(stack, StepAccept, "acepta")
+			return root
+			
+		case ll.grammar.IsTerminal(top.symbol):
+			if top.symbol != lookahead {
+				ll.fail(stack, []string{top.symbol}, fmt.Sprintf("Se esperaba %s", top.symbol))
+				return nil
+			}
+			ll.step(stack, StepMatch, "coincide "+lookahead)
+			token := ll.advance()
+			top.node.Value = token.Value
+			top.node.Line = token.Line
+			top.node.position = tokenPosition(token)
+			stack = stack[:len(stack)-1]
+			
+		default:
+			candidates := ll.grammar.Predict(top.symbol, lookahead)
+			if len(candidates) == 0 {
+				rule, _ := ll.grammar.Origin(top.symbol)
+				ll.fail(stack, ll.expected(top.symbol), fmt.Sprintf("No se esperaba %s al analizar %s", lookahead, rule))
+				return nil
+			}
+			chosen := candidates[0]
+			if len(candidates) > 1 {
+				chosen = ll.resolve(candidates)
+			}
+			production := ll.grammar.Productions[chosen]
+			ll.step(stack, StepExpand, production.String())
+			
+			top.node.Production = production.String()
+			stack = stack[:len(stack)-1]
+			for _, symbol := range production.Body {
+				top.node.Children = append(top.node.Children, &ParseTree{
+					Symbol:   symbol,
+					terminal: ll.grammar.IsTerminal(symbol),
+				})
+			}
+			for i := len(production.Body) - 1; i >= 0; i-- {
+				stack = append(stack, llEntry{symbol: production.Body[i], node: top.node.Children[i]})
+			}
+		}
+	}
+}
+
+// resolve elige entre las producciones de una celda con conflicto mirando
+// un token más, como el descenso recursivo: "x := 1", "f(x=1)" y
+// "Punto(x=0)" se reconocen por el símbolo que sigue al nombre, y en
+// "with (a, b):" los paréntesis agrupan los elementos solo si no están
+// vacíos y después del ')' viene ':'. En otro caso gana la última
+// alternativa, la más general.
+func (ll *predictive) resolve(candidates []int) int {
+	next := gramatica.EndMarker
+	if ll.current+1 < len(ll.input) {
+		next = ll.input[ll.current+1]
+	}
+	for _, candidate := range candidates {
+		body := ll.grammar.Productions[candidate].Body
+		if len(body) > 1 && ll.grammar.IsTerminal(body[1]) && body[1] == next {
+			return candidate
+		}
+		if len(body) > 0 && body[0] == "'('" && next != "')'" && ll.afterGroup().Value == ":" {
+			return candidate
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// expected devuelve los terminales con los que puede seguir el no terminal,
+// para el diagnóstico.
+func (ll *predictive) expected(nonTerminal string) []string {
+	var terminals []string
+	for _, terminal := range ll.grammar.InputTerminals() {
+		if len(ll.grammar.Predict(nonTerminal, terminal)) > 0 {
+			terminals = append(terminals, terminal)
+		}
+	}
+	return terminals
+}
+
+func (ll *predictive) step(stack []llEntry, kind, action string) {
+	symbols := make([]string, len(stack))
+	for i, entry := range stack {
+		symbols[i] = entry.symbol
+	}
+	ll.trace.record(symbols, ll.current, kind, action)
+}
+
+// record agrega a la traza un paso con la pila stack, anotando solo en qué
+// difiere de la del paso anterior.
+func (t *ParseTrace) record(stack []string, position int, kind, action string) {
+	common := 0
+	for common < len(stack) && common < len(t.stack) && stack[common] == t.stack[common] {
+		common++
+	}
+	t.Steps = append(t.Steps, ParseStep{
+		Pop:      len(t.stack) - common,
+		Push:     append([]string{}, stack[common:]...),
+		Position: position,
+		Kind:     kind,
+		Action:   action,
+	})
+	t.stack = append(t.stack[:common], stack[common:]...)
+}
+
+func (ll *predictive) fail(stack []llEntry, expected []string, message string) {
+	ll.step(stack, StepError, message)
+	names := make([]string, len(expected))
+	for i, terminal := range expected {
+		names[i] = strings.Trim(terminal, "'")
+	}
+	ll.report(Diagnostic{
+		Code:     ErrExpectedToken,
+		Message:  message,
+		Expected: strings.Join(names, " | "),
+	})
+}
+
+// grammarInput traduce los tokens a los terminales de la gramática y agrega
+// el fin de la entrada. match es un terminal propio solo al comienzo de una
+// sentencia que termina en ':', como decide isMatchStatement, y case al
+// comienzo de cada línea del bloque de un match, como en "case 1: pass".
+func grammarInput(p *Parser) []string {
+	input := make([]string, 0, len(p.tokens)+1)
+	// Niveles de indentación de los bloques de match abiertos
+	level := 0
+	var caseLevels []int
+	for i, token := range p.tokens {
+		symbol := terminalSymbol(token)
+		start := i == 0
+		if i > 0 {
+			switch p.tokens[i-1].Type {
+			case lexer.NEWLINE, lexer.INDENT, lexer.DEDENT:
+				start = true
+			}
+		}
+		switch token.Type {
+		case lexer.INDENT:
+			level++
+		case lexer.DEDENT:
+			level--
+			for len(caseLevels) > 0 && caseLevels[len(caseLevels)-1] > level {
+				caseLevels = caseLevels[:len(caseLevels)-1]
+			}
+		case lexer.IDENTIFIER:
+			p.current = i
+			switch {
+			case !start:
+			case token.Value == "match" && p.isSoftKeyword():
+				symbol = "'match'"
+				caseLevels = append(caseLevels, level+1)
+			case token.Value == "case" && len(caseLevels) > 0 && caseLevels[len(caseLevels)-1] == level:
+				symbol = "'case'"
+			}
+		}
+		input = append(input, symbol)
+	}
+	p.current = 0
+	return append(input, gramatica.EndMarker)
+}
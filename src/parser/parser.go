@@ -3,387 +3,2424 @@ package parser
 import (
 	"examencorte2/src/lexer"
 	"fmt"
-	"strings"
 )
 
-type ASTNode struct {
-	Type     string     `json:"type"`
-	Value    string     `json:"value,omitempty"`
-	Line     int        `json:"line"`
-	Children []*ASTNode `json:"children,omitempty"`
+type SyntaxResult struct {
+	AST       *ASTNode     `json:"ast"`
+	Errors    []Diagnostic `json:"errors"`
+	Success   bool         `json:"success"`
+	
+	// Código regenerado a partir del árbol: muestra cómo entendió el
+	// parser la entrada
+	Unparsed  string       `json:"unparsed"`
+	
+	// Árbol tipado del que se obtiene AST; lo usa el análisis semántico
+	Module    *Module      `json:"-"`
+	
+	// Árbol de derivación y pasos de la derivación por la izquierda; solo
+	// con AnalyzeWithDerivation, AnalyzeLL1 y AnalyzeLALR
+	ParseTree  *ParseTree       `json:"parse_tree,omitempty"`
+	Derivation []DerivationStep `json:"derivation,omitempty"`
+	
+	// Pasos del parser dirigido por tabla; solo con AnalyzeLL1 y AnalyzeLALR
+	Trace      *ParseTrace `json:"trace,omitempty"`
 }
 
-type SyntaxResult struct {
-	AST       *ASTNode `json:"ast"`
-	Errors    []string `json:"errors"`
-	Success   bool     `json:"success"`
-	ErrorLine int      `json:"error_line,omitempty"`
+// Diagnostic es un error de sintaxis con su código, el token esperado y el
+// encontrado, y la ubicación exacta en el código.
+type Diagnostic struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Expected string `json:"expected,omitempty"`
+	Found    string `json:"found,omitempty"`
+	Span     Span   `json:"span"`
+}
+
+// Span es un rango del código fuente. Las columnas empiezan en 1 y el final
+// no se incluye.
+type Span struct {
+	Line      int `json:"line"`
+	Column    int `json:"column"`
+	EndLine   int `json:"end_line"`
+	EndColumn int `json:"end_column"`
+}
+
+// Códigos de los diagnósticos de sintaxis
+const (
+	ErrExpectedToken      = "expected-token"
+	ErrExpectedExpression = "expected-expression"
+	ErrExpectedName       = "expected-name"
+	ErrExpectedBlock      = "expected-block"
+	ErrIndentation        = "indentation"
+	ErrInvalidSyntax      = "invalid-syntax"
+	ErrInvalidTarget      = "invalid-target"
+	ErrInvalidStarred     = "invalid-starred"
+	ErrInvalidParameters  = "invalid-parameters"
+	ErrInvalidArguments   = "invalid-arguments"
+	ErrInvalidPattern     = "invalid-pattern"
+	ErrInvalidStatement   = "invalid-statement"
+)
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("Error en línea %d: %s", d.Span.Line, d.Message)
 }
 
 type Parser struct {
 	tokens   []lexer.Token
 	current  int
-	errors   []string
+	errors   []Diagnostic
 	indent   int
+	
+	// Primer error de la sentencia actual; si la sentencia no puede
+	// completarse el parser entra en modo pánico y se recupera
+	panicMessage string
+	
+	// Pila de reglas abiertas del árbol de derivación; nil si no se registra
+	derivation []*ParseTree
+}
+
+// statementKeywords son las palabras que solo pueden iniciar una sentencia.
+// Sirven para resincronizar el parser después de un error.
+var statementKeywords = map[string]bool{
+	"def": true, "class": true, "try": true, "with": true, "while": true,
+	"return": true, "pass": true, "raise": true, "global": true,
+	"nonlocal": true, "del": true, "assert": true, "import": true,
+	"break": true, "continue": true,
+}
+
+// compoundKeywords inician las sentencias compuestas y sus cláusulas, pero
+// también pueden continuar una expresión, como en "[x for x in y]".
+var compoundKeywords = map[string]bool{
+	"if": true, "elif": true, "else": true, "for": true, "while": true,
+	"except": true, "finally": true,
+}
+
+func Analyze(tokens []lexer.Token) SyntaxResult {
+	return analyze(tokens, false)
+}
+
+func analyze(tokens []lexer.Token, derivation bool) SyntaxResult {
+	// Filtrar tokens de espacios en blanco y marcar la indentación
+	filteredTokens, layoutErrors := layoutTokens(tokens)
+	
+	parser := &Parser{
+		tokens:  filteredTokens,
+		current: 0,
+		errors:  layoutErrors,
+		indent:  0,
+	}
+	
+	// La raíz auxiliar recibe el nodo file_input
+	root := &ParseTree{}
+	if derivation {
+		parser.derivation = []*ParseTree{root}
+	}
+	
+	module := parser.parseProgram()
+	
+	result := SyntaxResult{
+		AST:      ToASTNode(module),
+		Errors:   parser.errors,
+		Success:  len(parser.errors) == 0,
+		Unparsed: Unparse(module),
+		Module:   module,
+	}
+	if derivation && len(root.Children) > 0 && finishParseTree(root.Children[0]) {
+		result.ParseTree = root.Children[0]
+		result.Derivation = LeftmostDerivation(result.ParseTree)
+	}
+	return result
+}
+
+// layoutTokens descarta espacios y agrega los tokens NEWLINE, INDENT y
+// DEDENT a partir de la línea y columna de cada token, como hace el
+// tokenizador de Python. Dentro de paréntesis, corchetes o llaves los
+// saltos de línea no terminan la sentencia.
+func layoutTokens(tokens []lexer.Token) ([]lexer.Token, []Diagnostic) {
+	var filtered []lexer.Token
+	errors := []Diagnostic{}
+	indents := []int{1}
+	depth := 0
+	// Si el primer paréntesis sin cerrar se abrió en el encabezado de un
+	// def o un class
+	header := false
+	var last, lineStart *lexer.Token
+	// Columna de la última línea mal indentada y nivel donde apareció
+	misaligned, misalignedLevel := 0, 0
+	
+	for i := range tokens {
+		token := tokens[i]
+		if token.Type == lexer.WHITESPACE || token.Type == lexer.NEWLINE {
+			continue
+		}
+		
+		// Un paréntesis sin cerrar no debe tragarse las sentencias
+		// siguientes, pero se da por terminado solo donde la línea no puede
+		// continuarlo: en una palabra que solo inicia sentencias, en una
+		// sentencia compuesta completa como "if a:" o después de un
+		// encabezado como "def f(:". En otro caso el parser reporta el
+		// paréntesis que falta y se recupera.
+		if last != nil && token.Line != last.Line && depth > 0 {
+			if token.Type == lexer.KEYWORD && statementKeywords[token.Value] ||
+				token.Type == lexer.KEYWORD && compoundKeywords[token.Value] && opensBlock(tokens, i) ||
+				header && last.Value == ":" && !continuesBracket(tokens, i) {
+				depth = 0
+			}
+		}
+		if last == nil || token.Line != last.Line {
+			lineStart = &tokens[i]
+		}
+		
+		if last == nil || (token.Line != last.Line && depth == 0) {
+			if last != nil {
+				filtered = append(filtered, lexer.Token{
+					Type:   lexer.NEWLINE,
+					Line:   last.Line,
+					Column: last.Column + len(last.Value),
+				})
+			}
+			
+			// Una línea con la misma indentación errónea que la anterior ya
+			// se reportó: se toma como del nivel actual
+			column := token.Column
+			if column == misaligned && indents[len(indents)-1] == misalignedLevel {
+				column = misalignedLevel
+			}
+			
+			top := indents[len(indents)-1]
+			if column > top {
+				indents = append(indents, column)
+				filtered = append(filtered, lexer.Token{Type: lexer.INDENT, Line: token.Line, Column: token.Column})
+			}
+			for column < indents[len(indents)-1] {
+				indents = indents[:len(indents)-1]
+				filtered = append(filtered, lexer.Token{Type: lexer.DEDENT, Line: token.Line, Column: token.Column})
+			}
+			if column != indents[len(indents)-1] {
+				// Sin INDENT que lo abra, el nivel no se apila: su DEDENT
+				// al final quedaría sin pareja
+				misaligned, misalignedLevel = column, indents[len(indents)-1]
+				errors = append(errors, Diagnostic{
+					Code:    ErrIndentation,
+					Message: "La indentación no coincide con ningún nivel anterior",
+					Found:   tokenDescription(token),
+					Span:    tokenSpan(token),
+				})
+			}
+		}
+		
+		switch token.Value {
+		case "(", "[", "{":
+			if depth == 0 {
+				header = lineStart.Type == lexer.KEYWORD && (lineStart.Value == "def" || lineStart.Value == "class")
+			}
+			depth++
+		case ")", "]", "}":
+			if depth > 0 {
+				depth--
+			}
+		}
+		
+		filtered = append(filtered, token)
+		last = &tokens[i]
+	}
+	
+	if last != nil {
+		end := lexer.Token{Line: last.Line, Column: last.Column + len(last.Value)}
+		end.Type = lexer.NEWLINE
+		filtered = append(filtered, end)
+		end.Type = lexer.DEDENT
+		for len(indents) > 1 {
+			indents = indents[:len(indents)-1]
+			filtered = append(filtered, end)
+		}
+	}
+	
+	return filtered, errors
+}
+
+func (p *Parser) parseProgram() *Module {
+	defer p.rule("file_input")()
+	program := &Module{
+		Position: Position{Line: 1, Column: 1, EndLine: 1, EndColumn: 1},
+		Body:     []Stmt{},
+	}
+	
+	for !p.isAtEnd() {
+		start := p.current
+		stmt := p.parseStatement()
+		if stmt != nil {
+			program.Body = append(program.Body, stmt)
+		} else if p.current == start {
+			// Avanza para evitar ciclo infinito si stmt es nil
+			p.advance()
+		}
+	}
+	
+	program.Position = p.spanFrom(program.Position)
+	return program
+}
+
+// parseStatement analiza una sentencia. Si la sentencia tiene un error de
+// sintaxis, el parser se recupera y devuelve un BadStmt en su lugar.
+func (p *Parser) parseStatement() Stmt {
+	defer p.rule("statement")()
+	start := p.current
+	stmt := p.dispatchStatement()
+	if stmt != nil || p.panicMessage == "" {
+		// Los errores que no interrumpen la sentencia no requieren recuperarse
+		p.panicMessage = ""
+		return stmt
+	}
+	return p.recover(start)
+}
+
+func (p *Parser) dispatchStatement() Stmt {
+	// Líneas vacías que quedaron tras un error
+	if p.matchType(lexer.NEWLINE) {
+		return nil
+	}
+	
+	if p.checkType(lexer.INDENT) {
+		p.error(ErrIndentation, "Indentación inesperada")
+		p.advance()
+		return p.parseIndentedBlock()
+	}
+	
+	if p.check("@") {
+		return p.parseDecorated()
+	}
+	
+	if p.match("def") {
+		return p.parseFunctionDef()
+	}
+	
+	if p.match("class") {
+		return p.parseClassDef()
+	}
+	
+	if p.match("async") {
+		return p.parseAsyncStatement()
+	}
+	
+	if p.match("for") {
+		return p.parseForStatement()
+	}
+	
+	if p.match("if") {
+		return p.parseIfStatement()
+	}
+	
+	if p.match("try") {
+		return p.parseTryStatement()
+	}
+	
+	if p.match("with") {
+		return p.parseWithStatement()
+	}
+	
+	if p.isMatchStatement() {
+		p.advance()
+		return p.parseMatchStatement()
+	}
+	
+	return p.parseSimpleStatement()
+}
+
+// parseSimpleStatement analiza una sentencia de una sola línea y consume el
+// salto de línea que la termina.
+func (p *Parser) parseSimpleStatement() Stmt {
+	defer p.rule("simple_stmt")()
+	var stmt Stmt
+	
+	if p.match("pass") {
+		stmt = &Pass{Position: tokenPosition(p.previous())}
+	} else if p.match("raise") {
+		stmt = p.parseRaiseStatement()
+	} else if p.match("global", "nonlocal") {
+		stmt = p.parseNameDeclaration()
+	} else if p.match("del") {
+		stmt = p.parseDeleteStatement()
+	} else if p.match("assert") {
+		stmt = p.parseAssertStatement()
+	} else {
+		stmt = p.parseAssignmentOrExpression()
+	}
+	
+	if stmt != nil {
+		p.endStatement()
+	}
+	return stmt
+}
+
+func (p *Parser) endStatement() {
+	if p.isAtEnd() || p.checkType(lexer.DEDENT) || p.matchType(lexer.NEWLINE) {
+		return
+	}
+	
+	if p.check(":=") {
+		p.error(ErrInvalidSyntax, "El operador ':=' debe ir entre paréntesis en esta posición")
+	} else {
+		p.errorExpected("NEWLINE", "Se esperaba un salto de línea al final de la sentencia")
+	}
+	for !p.isAtEnd() && !p.checkType(lexer.NEWLINE) {
+		p.advance()
+	}
+	p.matchType(lexer.NEWLINE)
+}
+
+func (p *Parser) parseFunctionDef() Stmt {
+	defer p.ruleAfter("funcdef", 1)()
+	start := tokenPosition(p.previous())
+	
+	if !p.checkType(lexer.IDENTIFIER) {
+		p.error(ErrExpectedName, "Se esperaba nombre de función")
+		return nil
+	}
+	
+	name := p.advance().Value
+	
+	if !p.match("(") {
+		p.errorExpected("(", "Se esperaba '(' después del nombre de función")
+		return nil
+	}
+	
+	params := p.parseParameters(")")
+	if params == nil {
+		return nil
+	}
+	
+	if !p.match(")") {
+		p.errorExpected(")", "Se esperaba ')' después de los parámetros")
+		return nil
+	}
+	
+	var returns Expr
+	if p.match("->") {
+		returns = p.parseExpression()
+		if returns == nil {
+			return nil
+		}
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de la definición de función")
+		return nil
+	}
+	
+	body := p.parseBlock()
+	
+	return &FunctionDef{
+		Position: p.spanFrom(start),
+		Name:     name,
+		Params:   params,
+		Returns:  returns,
+		Body:     body,
+	}
+}
+
+// parseDecorated analiza los decoradores "@expresión" que preceden a una
+// función o clase y los asigna a la definición.
+func (p *Parser) parseDecorated() Stmt {
+	defer p.rule("decorated")()
+	decorators := []*Decorator{}
+	for p.match("@") {
+		start := tokenPosition(p.previous())
+		expr := p.parseNamedExpression()
+		if expr == nil {
+			return nil
+		}
+		if !p.matchType(lexer.NEWLINE) {
+			p.errorExpected("NEWLINE", "Se esperaba un salto de línea después del decorador")
+			return nil
+		}
+		decorators = append(decorators, &Decorator{Position: p.spanFrom(start), Expr: expr})
+	}
+	
+	var definition Stmt
+	switch {
+	case p.match("def"):
+		definition = p.parseFunctionDef()
+	case p.match("class"):
+		definition = p.parseClassDef()
+	case p.check("async") && p.checkNext("def"):
+		start := tokenPosition(p.advance())
+		p.advance()
+		definition = p.parseFunctionDef()
+		if function, ok := definition.(*FunctionDef); ok {
+			function.Async = true
+			function.Position = p.spanFrom(start)
+		}
+	default:
+		p.errorExpected("def | class", "Se esperaba 'def' o 'class' después de los decoradores")
+		return nil
+	}
+	
+	switch definition := definition.(type) {
+	case *FunctionDef:
+		definition.Decorators = decorators
+	case *ClassDef:
+		definition.Decorators = decorators
+	}
+	return definition
+}
+
+// parseAsyncStatement analiza "async def", "async for" y "async with". La
+// palabra async ya fue consumida.
+func (p *Parser) parseAsyncStatement() Stmt {
+	defer p.ruleAfter("async_stmt", 1)()
+	start := tokenPosition(p.previous())
+	var stmt Stmt
+	switch {
+	case p.match("def"):
+		stmt = p.parseFunctionDef()
+	case p.match("for"):
+		stmt = p.parseForStatement()
+	case p.match("with"):
+		stmt = p.parseWithStatement()
+	default:
+		p.errorExpected("def | for | with", "Se esperaba 'def', 'for' o 'with' después de 'async'")
+		return nil
+	}
+	
+	// La sentencia empieza en async
+	switch stmt := stmt.(type) {
+	case *FunctionDef:
+		stmt.Async = true
+		stmt.Position = p.spanFrom(start)
+	case *For:
+		stmt.Async = true
+		stmt.Position = p.spanFrom(start)
+	case *With:
+		stmt.Async = true
+		stmt.Position = p.spanFrom(start)
+	}
+	return stmt
+}
+
+// parseClassDef analiza "class Nombre(bases):". Entre los paréntesis van
+// las bases y argumentos por nombre como metaclass=...
+func (p *Parser) parseClassDef() Stmt {
+	defer p.ruleAfter("classdef", 1)()
+	start := tokenPosition(p.previous())
+	
+	if !p.checkType(lexer.IDENTIFIER) {
+		p.error(ErrExpectedName, "Se esperaba nombre de clase")
+		return nil
+	}
+	
+	classNode := &ClassDef{Name: p.advance().Value}
+	
+	if p.match("(") {
+		bases, keywords, ok := p.parseArguments()
+		if !ok {
+			return nil
+		}
+		classNode.Bases = bases
+		classNode.Keywords = keywords
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de la definición de clase")
+		return nil
+	}
+	
+	classNode.Body = p.parseBlock()
+	classNode.Position = p.spanFrom(start)
+	return classNode
+}
+
+// parseForStatement analiza "for destino in iterable:" con su bloque y un
+// else opcional.
+func (p *Parser) parseForStatement() Stmt {
+	defer p.ruleAfter("for_stmt", 1)()
+	start := tokenPosition(p.previous())
+	
+	target := p.parseTargetList()
+	if target == nil {
+		return nil
+	}
+	if !p.match("in") {
+		p.errorExpected("in", "Se esperaba 'in' en la sentencia for")
+		return nil
+	}
+	iterable := p.parseExpressionList()
+	if iterable == nil {
+		return nil
+	}
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de la sentencia for")
+		return nil
+	}
+	
+	forNode := &For{
+		Target:   target,
+		Iter:     iterable,
+		Body:     p.parseBlock(),
+	}
+	
+	if p.check("else") {
+		elseClause := p.parseElseClause()
+		if elseClause == nil {
+			return nil
+		}
+		forNode.Else = elseClause
+	}
+	
+	forNode.Position = p.spanFrom(start)
+	return forNode
+}
+
+// isMatchStatement indica si la línea actual es una sentencia match. match
+// y case son palabras reservadas suaves: "match = 1" o "match(x)" siguen
+// siendo un nombre, así que solo es sentencia si la línea termina en ':'.
+func (p *Parser) isMatchStatement() bool {
+	if !p.checkType(lexer.IDENTIFIER) || p.peek().Value != "match" {
+		return false
+	}
+	return p.isSoftKeyword()
+}
+
+// isSoftKeyword indica si el nombre actual se usa como palabra reservada
+// suave: no le sigue ':', '=' ni '.' y la línea termina en ':'.
+func (p *Parser) isSoftKeyword() bool {
+	if p.checkNext(":") || p.checkNext("=") || p.checkNext(".") {
+		return false
+	}
+	
+	for i := p.current + 1; i < len(p.tokens); i++ {
+		if p.tokens[i].Type == lexer.NEWLINE {
+			return i > p.current+1 && p.tokens[i-1].Value == ":"
+		}
+	}
+	return false
+}
+
+// parseMatchStatement analiza "match sujeto:" y sus cláusulas case.
+func (p *Parser) parseMatchStatement() Stmt {
+	defer p.ruleAfter("match_stmt", 1)()
+	start := tokenPosition(p.previous())
+	
+	subjectStart := tokenPosition(p.peek())
+	subject := p.parseStarNamedExpression()
+	if subject == nil {
+		return nil
+	}
+	if p.check(",") {
+		elements := []Expr{subject}
+		for p.match(",") && !p.check(":") {
+			element := p.parseStarNamedExpression()
+			if element == nil {
+				return nil
+			}
+			elements = append(elements, element)
+		}
+		subject = &Tuple{Position: p.spanFrom(subjectStart), Elts: elements}
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después del sujeto de match")
+		return nil
+	}
+	if !p.matchType(lexer.NEWLINE) || !p.matchType(lexer.INDENT) {
+		p.error(ErrExpectedBlock, "Se esperaba un bloque indentado con cláusulas 'case'")
+		return nil
+	}
+	
+	matchNode := &Match{Subject: subject}
+	
+	for !p.isAtEnd() && !p.checkType(lexer.DEDENT) {
+		if !p.checkType(lexer.IDENTIFIER) || p.peek().Value != "case" {
+			p.errorExpected("case", "Se esperaba 'case' dentro de match")
+			return nil
+		}
+		p.advance()
+		
+		matchCase := p.parseMatchCase()
+		if matchCase == nil {
+			return nil
+		}
+		matchNode.Cases = append(matchNode.Cases, matchCase)
+	}
+	p.matchType(lexer.DEDENT)
+	
+	if len(matchNode.Cases) == 0 {
+		p.error(ErrInvalidStatement, "La sentencia match necesita al menos un 'case'")
+		return nil
+	}
+	
+	matchNode.Position = p.spanFrom(start)
+	return matchNode
+}
+
+// parseMatchCase analiza "case patrón [if guarda]:" y su bloque.
+func (p *Parser) parseMatchCase() *MatchCase {
+	defer p.ruleAfter("case_block", 1)()
+	start := tokenPosition(p.previous())
+	
+	pattern := p.parsePatterns()
+	if pattern == nil {
+		return nil
+	}
+	
+	caseNode := &MatchCase{Pattern: pattern}
+	
+	if p.match("if") {
+		guard := p.parseNamedExpression()
+		if guard == nil {
+			return nil
+		}
+		caseNode.Guard = guard
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después del patrón de case")
+		return nil
+	}
+	
+	caseNode.Body = p.parseBlock()
+	caseNode.Position = p.spanFrom(start)
+	return caseNode
+}
+
+// parsePatterns analiza el patrón de un case. "case a, *b:" es una
+// secuencia sin corchetes.
+func (p *Parser) parsePatterns() Pattern {
+	defer p.rule("patterns")()
+	start := tokenPosition(p.peek())
+	
+	first := p.parseMaybeStarPattern()
+	if first == nil {
+		return nil
+	}
+	if _, star := first.(*MatchStar); !p.check(",") && !star {
+		return first
+	}
+	
+	patterns := []Pattern{first}
+	for p.match(",") && !p.check(":") && !p.check("if") {
+		pattern := p.parseMaybeStarPattern()
+		if pattern == nil {
+			return nil
+		}
+		patterns = append(patterns, pattern)
+	}
+	return p.sequencePattern(start, patterns)
+}
+
+// sequencePattern arma un MatchSequence verificando que haya a lo sumo un
+// patrón con '*'.
+func (p *Parser) sequencePattern(start Position, patterns []Pattern) Pattern {
+	if p.violated(sequenceViolation(patterns)) {
+		return nil
+	}
+	
+	return &MatchSequence{
+		Position: p.spanFrom(start),
+		Patterns: patterns,
+	}
+}
+
+func (p *Parser) parseMaybeStarPattern() Pattern {
+	defer p.rule("maybe_star_pattern")()
+	if !p.match("*") {
+		return p.parsePattern()
+	}
+	
+	start := tokenPosition(p.previous())
+	if !p.checkType(lexer.IDENTIFIER) {
+		p.error(ErrExpectedName, "Se esperaba un nombre después de '*' en el patrón")
+		return nil
+	}
+	name := p.advance().Value
+	if name == "_" {
+		name = ""
+	}
+	return &MatchStar{Position: p.spanFrom(start), Name: name}
+}
+
+// parsePattern analiza "patrón | patrón ... [as nombre]".
+func (p *Parser) parsePattern() Pattern {
+	defer p.rule("pattern")()
+	start := tokenPosition(p.peek())
+	
+	alternatives := []Pattern{}
+	for {
+		pattern := p.parseClosedPattern()
+		if pattern == nil {
+			return nil
+		}
+		alternatives = append(alternatives, pattern)
+		if !p.match("|") {
+			break
+		}
+	}
+	
+	pattern := alternatives[0]
+	if len(alternatives) > 1 {
+		pattern = &MatchOr{Position: p.spanFrom(start), Patterns: alternatives}
+	}
+	
+	if !p.match("as") {
+		return pattern
+	}
+	if !p.checkType(lexer.IDENTIFIER) || p.peek().Value == "_" {
+		p.error(ErrExpectedName, "Se esperaba un nombre después de 'as' en el patrón")
+		return nil
+	}
+	name := p.advance().Value
+	return &MatchAs{
+		Position: p.spanFrom(start),
+		Pattern:  pattern,
+		Name:     name,
+	}
+}
+
+// parseClosedPattern analiza un patrón sin '|' ni 'as': literales, capturas,
+// el comodín _, valores con punto, secuencias, mapeos y clases.
+func (p *Parser) parseClosedPattern() Pattern {
+	defer p.rule("closed_pattern")()
+	start := tokenPosition(p.peek())
+	
+	switch {
+	case p.match("None", "True", "False"):
+		return &MatchSingleton{Position: start, Value: p.previous().Value}
+		
+	case p.checkType(lexer.NUMBER), p.checkType(lexer.STRING), p.check("-"):
+		value := p.parseFactor()
+		if value == nil {
+			return nil
+		}
+		if !isLiteralPattern(value) {
+			p.error(ErrInvalidPattern, "Solo se admiten literales en un patrón")
+			return nil
+		}
+		return &MatchValue{Position: p.spanFrom(start), Value: value}
+		
+	case p.match("("):
+		if p.match(")") {
+			return &MatchSequence{Position: p.spanFrom(start), Patterns: []Pattern{}}
+		}
+		first := p.parseMaybeStarPattern()
+		if first == nil {
+			return nil
+		}
+		// (patrón) solo agrupa; con coma es una secuencia
+		if _, star := first.(*MatchStar); p.match(")") && !star {
+			return first
+		}
+		patterns := []Pattern{first}
+		if p.previous().Value != ")" {
+			for p.match(",") && !p.check(")") {
+				pattern := p.parseMaybeStarPattern()
+				if pattern == nil {
+					return nil
+				}
+				patterns = append(patterns, pattern)
+			}
+			if !p.match(")") {
+				p.errorExpected(")", "Se esperaba ')' para cerrar el patrón")
+				return nil
+			}
+		}
+		return p.sequencePattern(start, patterns)
+		
+	case p.match("["):
+		patterns := []Pattern{}
+		for !p.check("]") {
+			pattern := p.parseMaybeStarPattern()
+			if pattern == nil {
+				return nil
+			}
+			patterns = append(patterns, pattern)
+			if !p.match(",") {
+				break
+			}
+		}
+		if !p.match("]") {
+			p.errorExpected("]", "Se esperaba ']' para cerrar el patrón")
+			return nil
+		}
+		return p.sequencePattern(start, patterns)
+		
+	case p.match("{"):
+		return p.parseMappingPattern(start)
+		
+	case p.checkType(lexer.IDENTIFIER):
+		name := p.advance().Value
+		if !p.check(".") && !p.check("(") {
+			if name == "_" {
+				return &MatchAs{Position: start}
+			}
+			return &MatchAs{Position: start, Name: name}
+		}
+		
+		var value Expr = &Identifier{Position: start, Name: name}
+		for p.match(".") {
+			if !p.checkType(lexer.IDENTIFIER) {
+				p.error(ErrExpectedName, "Se esperaba nombre de atributo después de '.'")
+				return nil
+			}
+			attr := p.advance().Value
+			value = &Attribute{
+				Position: p.spanFrom(start),
+				Value:    value,
+				Attr:     attr,
+			}
+		}
+		if p.match("(") {
+			return p.parseClassPattern(value)
+		}
+		return &MatchValue{Position: p.spanFrom(start), Value: value}
+	}
+	
+	p.error(ErrInvalidPattern, "Se esperaba un patrón")
+	return nil
+}
+
+// isLiteralPattern indica si la expresión es un número, un string o un
+// número negativo.
+func isLiteralPattern(value Expr) bool {
+	switch value := value.(type) {
+	case *Number, *String:
+		return true
+	case *UnaryOp:
+		_, number := value.Operand.(*Number)
+		return number
+	}
+	return false
+}
+
+// parseMappingPattern analiza "{clave: patrón, **resto}".
+func (p *Parser) parseMappingPattern(start Position) Pattern {
+	defer p.ruleAfter("mapping_pattern", 1)()
+	mapping := &MatchMapping{}
+	
+	for !p.check("}") {
+		if p.match("**") {
+			if !p.checkType(lexer.IDENTIFIER) || p.peek().Value == "_" {
+				p.error(ErrExpectedName, "Se esperaba un nombre después de '**' en el patrón")
+				return nil
+			}
+			mapping.Rest = p.advance().Value
+			p.match(",")
+			break
+		}
+		
+		key := p.parseClosedPattern()
+		if key == nil {
+			return nil
+		}
+		switch key.(type) {
+		case *MatchValue, *MatchSingleton:
+		default:
+			p.error(ErrInvalidPattern, "Las claves de un patrón de mapeo deben ser literales o valores con punto")
+			return nil
+		}
+		if !p.match(":") {
+			p.errorExpected(":", "Se esperaba ':' después de la clave del patrón")
+			return nil
+		}
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+		mapping.Keys = append(mapping.Keys, key)
+		mapping.Patterns = append(mapping.Patterns, value)
+		
+		if !p.match(",") {
+			break
+		}
+	}
+	
+	if !p.match("}") {
+		p.errorExpected("}", "Se esperaba '}' para cerrar el patrón")
+		return nil
+	}
+	mapping.Position = p.spanFrom(start)
+	return mapping
+}
+
+// parseClassPattern analiza "Clase(patrón, atributo=patrón)". Los patrones
+// posicionales deben ir antes que los por nombre.
+func (p *Parser) parseClassPattern(class Expr) Pattern {
+	classNode := &MatchClass{Cls: class}
+	
+	for !p.check(")") {
+		if p.checkType(lexer.IDENTIFIER) && p.checkNext("=") {
+			name := p.advance()
+			p.advance()
+			pattern := p.parsePattern()
+			if pattern == nil {
+				return nil
+			}
+			classNode.Keywords = append(classNode.Keywords, &MatchKeyword{
+				Position: p.spanFrom(tokenPosition(name)),
+				Name:     name.Value,
+				Pattern:  pattern,
+			})
+		} else {
+			if len(classNode.Keywords) > 0 {
+				p.error(ErrInvalidPattern, "Patrón posicional después de un patrón por nombre")
+				return nil
+			}
+			pattern := p.parsePattern()
+			if pattern == nil {
+				return nil
+			}
+			classNode.Patterns = append(classNode.Patterns, pattern)
+		}
+		
+		if !p.match(",") {
+			break
+		}
+	}
+	
+	if !p.match(")") {
+		p.errorExpected(")", "Se esperaba ')' para cerrar el patrón de clase")
+		return nil
+	}
+	classNode.Position = p.spanFrom(class.Pos())
+	return classNode
+}
+
+// parseElseClause analiza "else:" y su bloque.
+func (p *Parser) parseElseClause() *Clause {
+	defer p.rule("else_block")()
+	start := tokenPosition(p.advance())
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de 'else'")
+		return nil
+	}
+	body := p.parseBlock()
+	return &Clause{
+		Position: p.spanFrom(start),
+		Body:     body,
+	}
+}
+
+// parseParameters analiza la lista de parámetros hasta el token de cierre,
+// sin consumirlo. Kind indica la clase de cada parámetro y Default su valor
+// por defecto, si existe.
+func (p *Parser) parseParameters(closing string) []*Parameter {
+	defer p.rule("parameters")()
+	params := []*Parameter{}
+	rules := newParameterRules()
+	
+	for !p.check(closing) {
+		switch {
+		case p.match("/"):
+			if p.violated(rules.slash(params)) {
+				return nil
+			}
+			for _, param := range params {
+				param.Kind = PositionalOnlyParam
+			}
+			
+		case p.match("**"):
+			param := p.parseParameterName(KwArgsParam, rules)
+			if param == nil || !p.parseParameterAnnotation(param, closing) {
+				return nil
+			}
+			params = append(params, param)
+			p.match(",")
+			if p.violated(rules.kwargs(param, p.check(closing))) {
+				return nil
+			}
+			
+		case p.match("*"):
+			if p.violated(rules.star()) {
+				return nil
+			}
+			if p.checkType(lexer.IDENTIFIER) {
+				param := p.parseParameterName(VarArgsParam, rules)
+				if !p.parseParameterAnnotation(param, closing) {
+					return nil
+				}
+				params = append(params, param)
+			}
+			
+		default:
+			param := p.parseParameterName(rules.kind, rules)
+			if param == nil || !p.parseParameterAnnotation(param, closing) {
+				return nil
+			}
+			if p.match("=") {
+				value := p.parseExpression()
+				if value == nil {
+					return nil
+				}
+				param.Default = value
+			}
+			p.violated(rules.regular(param))
+			params = append(params, param)
+		}
+		
+		if !p.match(",") {
+			break
+		}
+	}
+	
+	if p.violated(rules.end(params)) {
+		return nil
+	}
+	
+	return params
+}
+
+func (p *Parser) parseParameterName(kind ParamKind, rules *parameterRules) *Parameter {
+	if !p.checkType(lexer.IDENTIFIER) {
+		p.error(ErrExpectedName, "Se esperaba nombre de parámetro")
+		return nil
+	}
+	
+	name := p.advance()
+	p.violated(rules.name(name.Value))
+	
+	return &Parameter{
+		Position: tokenPosition(name),
+		Name:     name.Value,
+		Kind:     kind,
+	}
+}
+
+// parseParameterAnnotation analiza la anotación ": tipo" de un parámetro.
+// Los parámetros de una lambda terminan en ':' y no admiten anotaciones.
+func (p *Parser) parseParameterAnnotation(param *Parameter, closing string) bool {
+	if closing != ")" || !p.match(":") {
+		return true
+	}
+	param.Annotation = p.parseExpression()
+	param.Position = p.spanFrom(param.Position)
+	return param.Annotation != nil
+}
+
+func (p *Parser) parseIfStatement() Stmt {
+	defer p.ruleAfter("if_stmt", 1)()
+	start := tokenPosition(p.previous())
+	
+	condition := p.parseNamedExpression()
+	if condition == nil {
+		return nil
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de la condición if")
+		return nil
+	}
+	
+	thenBranch := p.parseBlock()
+	
+	ifNode := &If{
+		Position: p.spanFrom(start),
+		Test:     condition,
+		Body:     thenBranch,
+	}
+	
+	return ifNode
+}
+
+func (p *Parser) parseBlock() *Block {
+	defer p.rule("block")()
+	// Bloque en la misma línea, por ejemplo: if x > 3: y = 1
+	if !p.matchType(lexer.NEWLINE) {
+		block := &Block{
+			Position: tokenPosition(p.peek()),
+			Body:     []Stmt{},
+		}
+		start := p.current
+		stmt := p.parseSimpleStatement()
+		if stmt == nil && p.panicMessage != "" {
+			stmt = p.recover(start)
+		}
+		p.panicMessage = ""
+		if stmt != nil {
+			block.Body = append(block.Body, stmt)
+		}
+		block.Position = p.spanFrom(block.Position)
+		return block
+	}
+	
+	if !p.matchType(lexer.INDENT) {
+		p.error(ErrExpectedBlock, "Se esperaba un bloque indentado")
+		return &Block{
+			Position: tokenPosition(p.peek()),
+			Body:     []Stmt{},
+		}
+	}
+	
+	return p.parseIndentedBlock()
+}
+
+// parseIndentedBlock analiza sentencias hasta el DEDENT que cierra el bloque.
+// El INDENT ya debe haber sido consumido.
+func (p *Parser) parseIndentedBlock() *Block {
+	block := &Block{
+		Position: tokenPosition(p.peek()),
+		Body:     []Stmt{},
+	}
+	
+	for !p.isAtEnd() && !p.checkType(lexer.DEDENT) {
+		start := p.current
+		stmt := p.parseStatement()
+		if stmt != nil {
+			block.Body = append(block.Body, stmt)
+		} else if p.current == start {
+			// Avanza para evitar ciclo infinito si stmt es nil
+			p.advance()
+		}
+	}
+	p.matchType(lexer.DEDENT)
+	
+	block.Position = p.spanFrom(block.Position)
+	return block
+}
+
+func (p *Parser) parseTryStatement() Stmt {
+	defer p.ruleAfter("try_stmt", 1)()
+	start := tokenPosition(p.previous())
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de 'try'")
+		return nil
+	}
+	
+	tryNode := &Try{Body: p.parseBlock()}
+	
+	catchAll := false
+	for p.check("except") {
+		if catchAll {
+			p.error(ErrInvalidStatement, "El 'except:' sin tipo debe ser el último manejador")
+		}
+		p.advance()
+		
+		handler := p.parseExceptHandler()
+		if handler == nil {
+			return nil
+		}
+		if handler.Type == nil {
+			catchAll = true
+		}
+		tryNode.Handlers = append(tryNode.Handlers, handler)
+	}
+	
+	if p.check("else") {
+		if len(tryNode.Handlers) == 0 {
+			p.error(ErrInvalidStatement, "La cláusula 'else' de try requiere al menos un 'except'")
+		}
+		elseClause := p.parseElseClause()
+		if elseClause == nil {
+			return nil
+		}
+		tryNode.Else = elseClause
+	}
+	
+	if p.check("finally") {
+		finallyStart := tokenPosition(p.advance())
+		if !p.match(":") {
+			p.errorExpected(":", "Se esperaba ':' después de 'finally'")
+			return nil
+		}
+		body := p.parseBlock()
+		tryNode.Finally = &Clause{
+			Position: p.spanFrom(finallyStart),
+			Body:     body,
+		}
+	}
+	
+	if len(tryNode.Handlers) == 0 && tryNode.Finally == nil {
+		p.errorExpected("except | finally", "Se esperaba 'except' o 'finally' después del bloque try")
+	}
+	
+	tryNode.Position = p.spanFrom(start)
+	return tryNode
+}
+
+// parseExceptHandler analiza "except [Tipo [as nombre]]:" y su bloque.
+func (p *Parser) parseExceptHandler() *ExceptHandler {
+	defer p.ruleAfter("except_block", 1)()
+	start := tokenPosition(p.previous())
+	handler := &ExceptHandler{}
+	
+	if !p.check(":") {
+		excType := p.parseExpression()
+		if excType == nil {
+			return nil
+		}
+		handler.Type = excType
+		
+		if p.match("as") {
+			if !p.checkType(lexer.IDENTIFIER) {
+				p.error(ErrExpectedName, "Se esperaba un nombre después de 'as'")
+				return nil
+			}
+			handler.Name = p.advance().Value
+		}
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de la cláusula except")
+		return nil
+	}
+	
+	handler.Body = p.parseBlock()
+	handler.Position = p.spanFrom(start)
+	return handler
+}
+
+// parseNameDeclaration analiza "global a, b" y "nonlocal a, b".
+func (p *Parser) parseNameDeclaration() Stmt {
+	keyword := p.previous()
+	defer p.ruleAfter(keyword.Value+"_stmt", 1)()
+	names := []*Identifier{}
+	
+	for {
+		if !p.checkType(lexer.IDENTIFIER) {
+			p.error(ErrExpectedName, fmt.Sprintf("Se esperaba un nombre después de '%s'", keyword.Value))
+			return nil
+		}
+		name := p.advance()
+		names = append(names, &Identifier{Position: tokenPosition(name), Name: name.Value})
+		if !p.match(",") {
+			break
+		}
+	}
+	
+	if keyword.Value == "nonlocal" {
+		return &Nonlocal{Position: p.spanFrom(tokenPosition(keyword)), Names: names}
+	}
+	return &Global{Position: p.spanFrom(tokenPosition(keyword)), Names: names}
+}
+
+// parseDeleteStatement analiza "del destino, ...".
+func (p *Parser) parseDeleteStatement() Stmt {
+	defer p.ruleAfter("del_stmt", 1)()
+	node := &Delete{Position: tokenPosition(p.previous())}
+	
+	for {
+		target := p.parseBitOr()
+		if target == nil || p.violated(deleteViolation(target)) {
+			return nil
+		}
+		node.Targets = append(node.Targets, target)
+		if !p.match(",") || p.isAtExpressionEnd() {
+			break
+		}
+	}
+	node.Position = p.spanFrom(node.Position)
+	return node
+}
+
+// parseAssertStatement analiza "assert condición [, mensaje]".
+func (p *Parser) parseAssertStatement() Stmt {
+	defer p.ruleAfter("assert_stmt", 1)()
+	node := &Assert{Position: tokenPosition(p.previous())}
+	
+	test := p.parseExpression()
+	if test == nil {
+		return nil
+	}
+	node.Test = test
+	
+	if p.match(",") {
+		message := p.parseExpression()
+		if message == nil {
+			return nil
+		}
+		node.Msg = message
+	}
+	
+	node.Position = p.spanFrom(node.Position)
+	return node
+}
+
+// parseRaiseStatement analiza "raise [excepción [from causa]]".
+func (p *Parser) parseRaiseStatement() Stmt {
+	defer p.ruleAfter("raise_stmt", 1)()
+	raiseNode := &Raise{Position: tokenPosition(p.previous())}
+	
+	if p.isAtEnd() || p.checkType(lexer.NEWLINE) || p.checkType(lexer.DEDENT) {
+		return raiseNode
+	}
+	
+	exc := p.parseExpression()
+	if exc == nil {
+		return nil
+	}
+	raiseNode.Exc = exc
+	
+	if p.match("from") {
+		cause := p.parseExpression()
+		if cause == nil {
+			return nil
+		}
+		raiseNode.Cause = cause
+	}
+	
+	raiseNode.Position = p.spanFrom(raiseNode.Position)
+	return raiseNode
+}
+
+// parseWithStatement analiza "with a as x, b as y:" y la forma entre
+// paréntesis "with (a as x, b as y):".
+func (p *Parser) parseWithStatement() Stmt {
+	defer p.ruleAfter("with_stmt", 1)()
+	start := tokenPosition(p.previous())
+	withNode := &With{}
+	
+	// "with (a, b):" es la forma entre paréntesis solo si tras el ')' viene
+	// ':'; en "with ():" el contexto es la tupla vacía
+	parenthesized := p.check("(") && !p.checkNext(")") && p.afterGroup().Value == ":"
+	if parenthesized {
+		p.advance()
+	}
+	
+	for {
+		item := p.parseWithItem()
+		if item == nil {
+			return nil
+		}
+		withNode.Items = append(withNode.Items, item)
+		
+		if !p.match(",") || (parenthesized && p.check(")")) {
+			break
+		}
+	}
+	
+	if parenthesized && !p.match(")") {
+		p.errorExpected(")", "Se esperaba ')' después de los elementos de with")
+		return nil
+	}
+	
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de la sentencia with")
+		return nil
+	}
+	
+	withNode.Body = p.parseBlock()
+	withNode.Position = p.spanFrom(start)
+	return withNode
+}
+
+func (p *Parser) parseWithItem() *WithItem {
+	defer p.rule("with_item")()
+	start := tokenPosition(p.peek())
+	context := p.parseExpression()
+	if context == nil {
+		return nil
+	}
+	
+	item := &WithItem{Context: context}
+	
+	// Como en el for, el destino puede ser un atributo, un índice o una
+	// tupla: "with a as (b, c):"
+	if p.match("as") {
+		target := p.parseStarTarget()
+		if target == nil {
+			return nil
+		}
+		if p.violated(starredViolation(target)) || p.violated(targetViolation(target)) {
+			return nil
+		}
+		item.Target = target
+	}
+	
+	item.Position = p.spanFrom(start)
+	return item
+}
+
+// Operadores de asignación aumentada
+var augmentedOperators = []string{
+	"+=", "-=", "*=", "/=", "//=", "%=", "**=", ">>=", "<<=", "&=", "|=", "^=", "@=",
+}
+
+// parseAssignmentOrExpression analiza una expresión y, si le sigue '=' o un
+// operador aumentado, la convierte en el destino de una asignación. En
+// "a = b = 0" el nodo Assign tiene dos destinos.
+func (p *Parser) parseAssignmentOrExpression() Stmt {
+	defer p.rule("expr_stmt")()
+	start := tokenPosition(p.peek())
+	expr := p.parseExpressionList()
+	if expr == nil {
+		return nil
+	}
+	
+	if p.match(augmentedOperators...) {
+		operator := p.previous().Value
+		if p.violated(augAssignViolation(operator, expr)) {
+			return nil
+		}
+		value := p.parseExpressionList()
+		if value == nil {
+			return nil
+		}
+		return &AugAssign{
+			Position: p.spanFrom(start),
+			Target:   expr,
+			Op:       operator,
+			Value:    value,
+		}
+	}
+	
+	if p.match(":") {
+		return p.parseAnnotatedAssignment(start, expr)
+	}
+	
+	if !p.check("=") {
+		return &ExprStmt{Position: p.spanFrom(start), Value: expr}
+	}
+	
+	assign := &Assign{}
+	for p.match("=") {
+		if p.violated(targetViolation(expr)) {
+			return nil
+		}
+		assign.Targets = append(assign.Targets, expr)
+		
+		expr = p.parseExpressionList()
+		if expr == nil {
+			return nil
+		}
+	}
+	assign.Value = expr
+	assign.Position = p.spanFrom(start)
+	return assign
+}
+
+// parseAnnotatedAssignment analiza "destino: tipo [= valor]".
+func (p *Parser) parseAnnotatedAssignment(start Position, target Expr) Stmt {
+	defer p.ruleAfter("annassign", 1)()
+	if p.violated(annAssignViolation(target)) {
+		return nil
+	}
+	
+	annotation := p.parseExpression()
+	if annotation == nil {
+		return nil
+	}
+	
+	node := &AnnAssign{
+		Target:     target,
+		Annotation: annotation,
+	}
+	
+	if p.match("=") {
+		value := p.parseExpressionList()
+		if value == nil {
+			return nil
+		}
+		node.Value = value
+	}
+	
+	node.Position = p.spanFrom(start)
+	return node
+}
+
+// parseExpressionList analiza expresiones separadas por comas. Con más de
+// una expresión, o con coma final, el resultado es una tupla sin paréntesis.
+func (p *Parser) parseExpressionList() Expr {
+	defer p.rule("star_expressions")()
+	start := tokenPosition(p.peek())
+	first := p.parseStarExpression()
+	if first == nil {
+		return nil
+	}
+	
+	if !p.check(",") {
+		p.violated(starredViolation(first))
+		return first
+	}
+	
+	tuple := &Tuple{Elts: []Expr{first}}
+	for p.match(",") {
+		if p.isAtExpressionEnd() {
+			break
+		}
+		element := p.parseStarExpression()
+		if element == nil {
+			return nil
+		}
+		tuple.Elts = append(tuple.Elts, element)
+	}
+	
+	tuple.Position = p.spanFrom(start)
+	return tuple
+}
+
+// parseStarExpression analiza una expresión que puede llevar '*' para
+// desempaquetar, como en [*a, *b].
+func (p *Parser) parseStarExpression() Expr {
+	defer p.rule("star_expression")()
+	return p.parseStarred(p.parseExpression)
+}
+
+// parseStarNamedExpression es como parseStarExpression pero admite además
+// el operador morsa, que solo puede aparecer sin paréntesis dentro de
+// colecciones, argumentos y condiciones.
+func (p *Parser) parseStarNamedExpression() Expr {
+	defer p.rule("star_named_expression")()
+	return p.parseStarred(p.parseNamedExpression)
+}
+
+func (p *Parser) parseStarred(parse func() Expr) Expr {
+	if p.match("*") {
+		start := tokenPosition(p.previous())
+		value := p.parseBitOr()
+		if value == nil {
+			return nil
+		}
+		return &Starred{Position: p.spanFrom(start), Value: value}
+	}
+	return parse()
+}
+
+// parseNamedExpression analiza "nombre := valor" o una expresión normal.
+func (p *Parser) parseNamedExpression() Expr {
+	defer p.rule("named_expression")()
+	if !p.checkType(lexer.IDENTIFIER) || !p.checkNext(":=") {
+		return p.parseExpression()
+	}
+	
+	name := p.advance()
+	p.advance()
+	value := p.parseExpression()
+	if value == nil {
+		return nil
+	}
+	
+	return &NamedExpr{
+		Position: p.spanFrom(tokenPosition(name)),
+		Target:   &Identifier{Position: tokenPosition(name), Name: name.Value},
+		Value:    value,
+	}
+}
+
+// parseExpression analiza una expresión completa: lambda o expresión
+// condicional "valor if condición else otro".
+func (p *Parser) parseExpression() Expr {
+	defer p.rule("expression")()
+	if p.match("lambda") {
+		return p.parseLambda()
+	}
+	
+	start := tokenPosition(p.peek())
+	expr := p.parseDisjunction()
+	if expr == nil || !p.match("if") {
+		return expr
+	}
+	
+	test := p.parseDisjunction()
+	if test == nil {
+		return nil
+	}
+	if !p.match("else") {
+		p.errorExpected("else", "Se esperaba 'else' en la expresión condicional")
+		return nil
+	}
+	orElse := p.parseExpression()
+	if orElse == nil {
+		return nil
+	}
+	
+	return &IfExp{
+		Position: p.spanFrom(start),
+		Test:     test,
+		Body:     expr,
+		Orelse:   orElse,
+	}
+}
+
+// parseLambda analiza "lambda parámetros: expresión". La palabra lambda ya
+// fue consumida.
+func (p *Parser) parseLambda() Expr {
+	defer p.ruleAfter("lambdef", 1)()
+	start := tokenPosition(p.previous())
+	
+	params := p.parseParameters(":")
+	if params == nil {
+		return nil
+	}
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' después de los parámetros de lambda")
+		return nil
+	}
+	
+	body := p.parseExpression()
+	if body == nil {
+		return nil
+	}
+	
+	return &Lambda{
+		Position: p.spanFrom(start),
+		Params:   params,
+		Body:     body,
+	}
+}
+
+// parseDisjunction y parseConjunction agrupan todos los operandos de una
+// cadena de 'or' o de 'and' en un único nodo BoolOp.
+func (p *Parser) parseDisjunction() Expr {
+	defer p.rule("disjunction")()
+	return p.parseBoolOp("or", p.parseConjunction)
+}
+
+func (p *Parser) parseConjunction() Expr {
+	defer p.rule("conjunction")()
+	return p.parseBoolOp("and", p.parseInversion)
+}
+
+func (p *Parser) parseBoolOp(operator string, next func() Expr) Expr {
+	start := tokenPosition(p.peek())
+	expr := next()
+	if expr == nil || !p.check(operator) {
+		return expr
+	}
+	
+	boolOp := &BoolOp{
+		Op:     operator,
+		Values: []Expr{expr},
+	}
+	for p.match(operator) {
+		operand := next()
+		if operand == nil {
+			return nil
+		}
+		boolOp.Values = append(boolOp.Values, operand)
+	}
+	boolOp.Position = p.spanFrom(start)
+	return boolOp
+}
+
+func (p *Parser) parseInversion() Expr {
+	defer p.rule("inversion")()
+	if p.match("not") {
+		start := tokenPosition(p.previous())
+		operand := p.parseInversion()
+		if operand == nil {
+			return nil
+		}
+		return &UnaryOp{
+			Position: p.spanFrom(start),
+			Op:       "not",
+			Operand:  operand,
+		}
+	}
+	return p.parseComparison()
+}
+
+// parseComparison analiza comparaciones. Una comparación simple es un
+// BinaryOp y una cadena como a < b <= c es un nodo Compare.
+func (p *Parser) parseComparison() Expr {
+	defer p.rule("comparison")()
+	start := tokenPosition(p.peek())
+	expr := p.parseBitOr()
+	if expr == nil {
+		return nil
+	}
+	
+	comparators := []Expr{}
+	operators := []string{}
+	for {
+		operator := p.matchComparisonOperator()
+		if operator == "" {
+			break
+		}
+		right := p.parseBitOr()
+		if right == nil {
+			return nil
+		}
+		comparators = append(comparators, right)
+		operators = append(operators, operator)
+	}
+	
+	switch len(operators) {
+	case 0:
+		return expr
+	case 1:
+		return &BinaryOp{
+			Position: p.spanFrom(start),
+			Op:       operators[0],
+			Left:     expr,
+			Right:    comparators[0],
+		}
+	}
+	return &Compare{
+		Position:    p.spanFrom(start),
+		Left:        expr,
+		Ops:         operators,
+		Comparators: comparators,
+	}
+}
+
+func (p *Parser) matchComparisonOperator() string {
+	if p.match(">", "<", ">=", "<=", "==", "!=", "in") {
+		return p.previous().Value
+	}
+	if p.check("not") && p.checkNext("in") {
+		p.advance()
+		p.advance()
+		return "not in"
+	}
+	if p.match("is") {
+		if p.match("not") {
+			return "is not"
+		}
+		return "is"
+	}
+	return ""
+}
+
+func (p *Parser) parseBitOr() Expr {
+	defer p.rule("bitwise_or")()
+	return p.parseBinary(p.parseBitXor, "|")
+}
+
+func (p *Parser) parseBitXor() Expr {
+	defer p.rule("bitwise_xor")()
+	return p.parseBinary(p.parseBitAnd, "^")
+}
+
+func (p *Parser) parseBitAnd() Expr {
+	defer p.rule("bitwise_and")()
+	return p.parseBinary(p.parseShift, "&")
+}
+
+func (p *Parser) parseShift() Expr {
+	defer p.rule("shift_expr")()
+	return p.parseBinary(p.parseTerm, "<<", ">>")
+}
+
+func (p *Parser) parseTerm() Expr {
+	defer p.rule("sum")()
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *Parser) parseProduct() Expr {
+	defer p.rule("term")()
+	return p.parseBinary(p.parseFactor, "*", "/", "//", "%", "@")
+}
+
+// parseBinary analiza operaciones binarias asociativas por la izquierda con
+// los operadores dados, cuyos operandos se leen con next.
+func (p *Parser) parseBinary(next func() Expr, operators ...string) Expr {
+	start := tokenPosition(p.peek())
+	expr := next()
+	
+	for expr != nil && p.match(operators...) {
+		operator := p.previous().Value
+		right := next()
+		if right == nil {
+			return nil
+		}
+		expr = &BinaryOp{
+			Position: p.spanFrom(start),
+			Op:       operator,
+			Left:     expr,
+			Right:    right,
+		}
+	}
+	
+	return expr
+}
+
+func (p *Parser) parseFactor() Expr {
+	defer p.rule("factor")()
+	if p.match("-", "+", "~") {
+		operator := p.previous()
+		operand := p.parseFactor()
+		if operand == nil {
+			return nil
+		}
+		return &UnaryOp{
+			Position: p.spanFrom(tokenPosition(operator)),
+			Op:       operator.Value,
+			Operand:  operand,
+		}
+	}
+	
+	return p.parsePower()
+}
+
+// parsePower analiza '**', que es asociativo por la derecha y se aplica
+// antes que el signo de la izquierda: -2 ** 2 es -(2 ** 2).
+func (p *Parser) parsePower() Expr {
+	defer p.rule("power")()
+	start := tokenPosition(p.peek())
+	base := p.parseAwait()
+	if base == nil || !p.match("**") {
+		return base
+	}
+	
+	exponent := p.parseFactor()
+	if exponent == nil {
+		return nil
+	}
+	return &BinaryOp{
+		Position: p.spanFrom(start),
+		Op:       "**",
+		Left:     base,
+		Right:    exponent,
+	}
+}
+
+func (p *Parser) parseAwait() Expr {
+	defer p.rule("await_primary")()
+	if !p.match("await") {
+		start := tokenPosition(p.peek())
+		return p.parsePostfix(start, p.parsePrimary())
+	}
+	
+	await := tokenPosition(p.previous())
+	start := tokenPosition(p.peek())
+	value := p.parsePostfix(start, p.parsePrimary())
+	if value == nil {
+		return nil
+	}
+	return &Await{Position: p.spanFrom(await), Value: value}
+}
+
+// parsePostfix aplica a una expresión las llamadas, accesos a atributo e
+// índices que la siguen, como en obj.items[0].name() o f()(). Cada uno
+// ocupa desde start, el comienzo de la expresión, hasta su cierre.
+func (p *Parser) parsePostfix(start Position, expr Expr) Expr {
+	defer p.ruleAfter("atom_expr", 1)()
+	for expr != nil {
+		// Cada llamada, atributo o índice es un trailer en la derivación
+		closeTrailer := p.rule("trailer")
+		switch {
+		case p.match("("):
+			expr = p.parseCall(start, expr)
+			
+		case p.match("."):
+			if !p.checkType(lexer.IDENTIFIER) {
+				p.error(ErrExpectedName, "Se esperaba nombre de atributo después de '.'")
+				return nil
+			}
+			attr := p.advance().Value
+			expr = &Attribute{
+				Position: p.spanFrom(start),
+				Value:    expr,
+				Attr:     attr,
+			}
+			
+		case p.match("["):
+			expr = p.parseSubscript(start, expr)
+			
+		default:
+			closeTrailer()
+			return expr
+		}
+		closeTrailer()
+	}
+	return expr
 }
 
-func Analyze(tokens []lexer.Token) SyntaxResult {
-	// Filtrar tokens de espacios en blanco para el análisis sintáctico
-	filteredTokens := filterTokens(tokens)
-	
-	parser := &Parser{
-		tokens:  filteredTokens,
-		current: 0,
-		errors:  []string{},
-		indent:  0,
+// parseCall analiza los argumentos de una llamada a callee. El '(' ya fue
+// consumido.
+func (p *Parser) parseCall(start Position, callee Expr) Expr {
+	args, keywords, ok := p.parseArguments()
+	if !ok {
+		return nil
 	}
 	
-	ast := parser.parseProgram()
-	
-	return SyntaxResult{
-		AST:     ast,
-		Errors:  parser.errors,
-		Success: len(parser.errors) == 0,
-		ErrorLine: parser.getErrorLine(),
+	return &Call{
+		Position: p.spanFrom(start),
+		Func:     callee,
+		Args:     args,
+		Keywords: keywords,
 	}
 }
 
-func filterTokens(tokens []lexer.Token) []lexer.Token {
-	var filtered []lexer.Token
-	for _, token := range tokens {
-		if token.Type != lexer.WHITESPACE && token.Type != lexer.NEWLINE {
-			filtered = append(filtered, token)
+// parseArguments analiza los argumentos de una llamada o las bases de una
+// clase y consume el ')' final. Devuelve por separado los argumentos
+// posicionales y los argumentos por nombre.
+func (p *Parser) parseArguments() ([]Expr, []*Keyword, bool) {
+	open := tokenPosition(p.previous())
+	args := []Expr{}
+	keywords := []*Keyword{}
+	rules := newArgumentRules()
+	
+	for !p.check(")") {
+		arg := p.parseArgument()
+		if arg == nil {
+			return nil, nil, false
+		}
+		
+		p.violated(rules.add(arg))
+		keyword, isKeyword := arg.(*Keyword)
+		
+		// f(x for x in datos): el generador sin paréntesis debe ser el único
+		// argumento, y como en CPython ocupa también los paréntesis de la
+		// llamada
+		if p.checkComprehension() {
+			if len(args) > 0 || len(keywords) > 0 || isKeyword {
+				p.violated(generatorViolation())
+				return nil, nil, false
+			}
+			generator := p.parseComprehension("GeneratorExp", open, []Expr{arg.(Expr)}, ")")
+			if generator == nil {
+				return nil, nil, false
+			}
+			return []Expr{generator}, keywords, true
+		}
+		
+		if isKeyword {
+			keywords = append(keywords, keyword)
+		} else {
+			args = append(args, arg.(Expr))
+		}
+		if !p.match(",") {
+			break
 		}
 	}
-	return filtered
+	
+	if !p.match(")") {
+		p.errorExpected(")", "Se esperaba ')' después de los argumentos")
+		return nil, nil, false
+	}
+	
+	return args, keywords, true
 }
 
-func (p *Parser) parseProgram() *ASTNode {
-	program := &ASTNode{
-		Type:     "Program",
-		Children: []*ASTNode{},
-		Line:     1,
+// parseArgument analiza un argumento de llamada: posicional, *iterable,
+// nombre=valor o **diccionario. Los dos últimos se devuelven como Keyword.
+func (p *Parser) parseArgument() Node {
+	defer p.rule("argument")()
+	if p.match("**") {
+		start := tokenPosition(p.previous())
+		value := p.parseExpression()
+		if value == nil {
+			return nil
+		}
+		return &Keyword{Position: p.spanFrom(start), Value: value}
 	}
 	
-	for !p.isAtEnd() {
-		stmt := p.parseStatement()
-		if stmt != nil {
-			program.Children = append(program.Children, stmt)
+	if p.checkType(lexer.IDENTIFIER) && p.checkNext("=") {
+		name := p.advance()
+		p.advance()
+		value := p.parseExpression()
+		if value == nil {
+			return nil
+		}
+		return &Keyword{
+			Position: p.spanFrom(tokenPosition(name)),
+			Name:     name.Value,
+			Value:    value,
 		}
 	}
 	
-	return program
+	arg := p.parseStarNamedExpression()
+	if arg == nil {
+		return nil
+	}
+	return arg
 }
 
-func (p *Parser) parseStatement() *ASTNode {
-	if p.match("def") {
-		return p.parseFunctionDef()
+// parseSubscript analiza el índice de valor[...], que puede ser una
+// expresión, una rebanada o varios separados por comas. El '[' ya fue
+// consumido.
+func (p *Parser) parseSubscript(start Position, value Expr) Expr {
+	indexStart := tokenPosition(p.peek())
+	index := p.parseSliceItem()
+	if index == nil {
+		return nil
 	}
 	
-	if p.match("if") {
-		return p.parseIfStatement()
+	if p.check(",") {
+		tuple := &Tuple{Elts: []Expr{index}}
+		for p.match(",") && !p.check("]") {
+			item := p.parseSliceItem()
+			if item == nil {
+				return nil
+			}
+			tuple.Elts = append(tuple.Elts, item)
+		}
+		tuple.Position = p.spanFrom(indexStart)
+		index = tuple
+	}
+	
+	if !p.match("]") {
+		p.errorExpected("]", "Se esperaba ']' después del índice")
+		return nil
+	}
+	
+	return &Subscript{
+		Position: p.spanFrom(start),
+		Value:    value,
+		Index:    index,
 	}
+}
+
+// parseSliceItem analiza un índice o una rebanada inicio:fin:paso.
+func (p *Parser) parseSliceItem() Expr {
+	defer p.rule("slice")()
+	start := tokenPosition(p.peek())
+	parts := []Expr{nil, nil, nil}
 	
-	if p.check("print") {
-		return p.parseExpressionStatement()
+	if !p.check(":") {
+		lower := p.parseNamedExpression()
+		if lower == nil || !p.check(":") {
+			return lower
+		}
+		parts[0] = lower
 	}
 	
-	if p.checkType(lexer.IDENTIFIER) {
-		return p.parseAssignmentOrExpression()
+	for i := 1; i < len(parts) && p.match(":"); i++ {
+		if p.check(":") || p.check("]") || p.check(",") {
+			continue
+		}
+		part := p.parseExpression()
+		if part == nil {
+			return nil
+		}
+		parts[i] = part
 	}
 	
-	return p.parseExpressionStatement()
+	return &Slice{
+		Position: p.spanFrom(start),
+		Lower:    parts[0],
+		Upper:    parts[1],
+		Step:     parts[2],
+	}
 }
 
-func (p *Parser) parseFunctionDef() *ASTNode {
-	line := p.previous().Line
+func (p *Parser) parsePrimary() Expr {
+	defer p.rule("atom")()
+	if p.match("(") {
+		return p.parseParenthesized()
+	}
 	
-	if !p.checkType(lexer.IDENTIFIER) {
-		p.error("Se esperaba nombre de función")
-		return nil
+	if p.match("[") {
+		return p.parseListDisplay()
 	}
 	
-	name := p.advance().Value
+	if p.match("{") {
+		return p.parseDictOrSet()
+	}
 	
-	if !p.match("(") {
-		p.error("Se esperaba '(' después del nombre de función")
-		return nil
+	if p.checkType(lexer.NUMBER) {
+		token := p.advance()
+		return &Number{Position: tokenPosition(token), Value: token.Value}
 	}
 	
-	params := []*ASTNode{}
-	if !p.check(")") {
-		for {
-			if !p.checkType(lexer.IDENTIFIER) {
-				p.error("Se esperaba nombre de parámetro")
-				break
-			}
-			param := &ASTNode{
-				Type:  "Parameter",
-				Value: p.advance().Value,
-				Line:  p.previous().Line,
-			}
-			params = append(params, param)
-			
-			if !p.match(",") {
-				break
-			}
-		}
+	if p.checkType(lexer.STRING) {
+		token := p.advance()
+		return &String{Position: tokenPosition(token), Value: token.Value}
 	}
 	
-	if !p.match(")") {
-		p.error("Se esperaba ')' después de los parámetros")
-		return nil
+	if p.match("True", "False") {
+		token := p.previous()
+		return &Boolean{Position: tokenPosition(token), Value: token.Value == "True"}
 	}
 	
-	if !p.match(":") {
-		p.error("Se esperaba ':' después de la definición de función")
-		return nil
+	if p.match("None") {
+		return &None{Position: tokenPosition(p.previous())}
 	}
 	
-	body := p.parseBlock()
+	// "..." se usa en anotaciones como tuple[int, ...]
+	if p.match("...") {
+		return &Ellipsis{Position: tokenPosition(p.previous())}
+	}
 	
-	return &ASTNode{
-		Type:  "FunctionDef",
-		Value: name,
-		Line:  line,
-		Children: append(params, body),
+	// print es palabra reservada en el léxico pero se usa como función
+	if p.checkType(lexer.IDENTIFIER) || p.check("print") {
+		token := p.advance()
+		return &Identifier{Position: tokenPosition(token), Name: token.Value}
 	}
+	
+	p.error(ErrExpectedExpression, "Se esperaba expresión")
+	return nil
 }
 
-func (p *Parser) parseIfStatement() *ASTNode {
-	line := p.previous().Line
+// parseParenthesized analiza lo que sigue a '(': una tupla, la tupla vacía
+// o una expresión agrupada. El '(' ya fue consumido.
+func (p *Parser) parseParenthesized() Expr {
+	start := tokenPosition(p.previous())
 	
-	condition := p.parseExpression()
-	if condition == nil {
-		return nil
+	if p.match(")") {
+		return &Tuple{Position: p.spanFrom(start), Elts: []Expr{}}
 	}
 	
-	if !p.match(":") {
-		p.error("Se esperaba ':' después de la condición if")
+	first := p.parseStarNamedExpression()
+	if first == nil {
 		return nil
 	}
 	
-	thenBranch := p.parseBlock()
+	if p.checkComprehension() {
+		return p.parseComprehension("GeneratorExp", start, []Expr{first}, ")")
+	}
 	
-	ifNode := &ASTNode{
-		Type:     "IfStatement",
-		Line:     line,
-		Children: []*ASTNode{condition, thenBranch},
+	if p.match(",") {
+		elements := p.parseElements([]Expr{first}, ")")
+		if elements == nil {
+			return nil
+		}
+		return &Tuple{Position: p.spanFrom(start), Elts: elements}
 	}
 	
-	return ifNode
+	p.violated(starredViolation(first))
+	if !p.match(")") {
+		p.errorExpected(")", "Se esperaba ')' después de la expresión")
+		return nil
+	}
+	return first
 }
 
-func (p *Parser) parseBlock() *ASTNode {
-    block := &ASTNode{
-        Type:     "Block",
-        Children: []*ASTNode{},
-        Line:     p.peek().Line,
-    }
-
-    for !p.isAtEnd() && !p.check("def") && !p.check("if") &&
-        !p.checkNext("def") && !p.checkNext("if") {
-        stmt := p.parseStatement()
-        if stmt != nil {
-            block.Children = append(block.Children, stmt)
-        } else {
-            // Avanza para evitar ciclo infinito si stmt es nil
-            p.advance()
-        }
-        if p.current >= len(p.tokens)-1 {
-            break
-        }
-    }
-
-    return block
+// parseListDisplay analiza una lista o una comprensión de lista. El '[' ya
+// fue consumido.
+func (p *Parser) parseListDisplay() Expr {
+	start := tokenPosition(p.previous())
+	elements := []Expr{}
+	
+	if !p.check("]") {
+		first := p.parseStarNamedExpression()
+		if first == nil {
+			return nil
+		}
+		if p.checkComprehension() {
+			return p.parseComprehension("ListComp", start, []Expr{first}, "]")
+		}
+		elements = append(elements, first)
+		if !p.match(",") && !p.check("]") {
+			p.errorExpected("]", "Se esperaba ']' al final de la colección")
+			return nil
+		}
+	}
+	
+	elements = p.parseElements(elements, "]")
+	if elements == nil {
+		return nil
+	}
+	return &List{Position: p.spanFrom(start), Elts: elements}
 }
 
-func (p *Parser) parseAssignmentOrExpression() *ASTNode {
-	if p.current + 1 < len(p.tokens) && p.tokens[p.current + 1].Value == "=" {
-		return p.parseAssignment()
-	}
-	return p.parseExpressionStatement()
+func (p *Parser) checkComprehension() bool {
+	return p.check("for") || (p.check("async") && p.checkNext("for"))
 }
 
-func (p *Parser) parseAssignment() *ASTNode {
-	line := p.peek().Line
-	
-	if !p.checkType(lexer.IDENTIFIER) {
-		p.error("Se esperaba identificador en asignación")
+// parseComprehension analiza las cláusulas "for ... in ... if ..." que
+// siguen al elemento de una comprensión y consume el cierre. kind indica el
+// nodo a construir, que empieza en start; en DictComp los elementos son la
+// clave y el valor.
+func (p *Parser) parseComprehension(kind string, start Position, elements []Expr, closing string) Expr {
+	if p.violated(comprehensionViolation(elements)) {
 		return nil
 	}
 	
-	name := p.advance().Value
-	
-	if !p.match("=") {
-		p.error("Se esperaba '=' en asignación")
-		return nil
+	generators := []*Comprehension{}
+	for p.checkComprehension() {
+		closeClause := p.rule("for_if_clause")
+		clauseStart := tokenPosition(p.peek())
+		clause := &Comprehension{Async: p.match("async")}
+		p.advance()
+		
+		target := p.parseTargetList()
+		if target == nil {
+			return nil
+		}
+		if !p.match("in") {
+			p.errorExpected("in", "Se esperaba 'in' en la comprensión")
+			return nil
+		}
+		// El iterable no puede ser una expresión condicional sin paréntesis:
+		// el 'if' que le sigue es un filtro de la comprensión.
+		iterable := p.parseDisjunction()
+		if iterable == nil {
+			return nil
+		}
+		clause.Target = target
+		clause.Iter = iterable
+		
+		for p.match("if") {
+			condition := p.parseDisjunction()
+			if condition == nil {
+				return nil
+			}
+			clause.Ifs = append(clause.Ifs, condition)
+		}
+		
+		clause.Position = p.spanFrom(clauseStart)
+		generators = append(generators, clause)
+		closeClause()
 	}
 	
-	value := p.parseExpression()
-	if value == nil {
+	if !p.match(closing) {
+		p.errorExpected(closing, fmt.Sprintf("Se esperaba '%s' al final de la comprensión", closing))
 		return nil
 	}
 	
-	return &ASTNode{
-		Type:  "Assignment",
-		Value: name,
-		Line:  line,
-		Children: []*ASTNode{value},
+	position := p.spanFrom(start)
+	switch kind {
+	case "ListComp":
+		return &ListComp{Position: position, Elt: elements[0], Generators: generators}
+	case "SetComp":
+		return &SetComp{Position: position, Elt: elements[0], Generators: generators}
+	case "DictComp":
+		return &DictComp{Position: position, Key: elements[0], Value: elements[1], Generators: generators}
 	}
+	return &GeneratorExp{Position: position, Elt: elements[0], Generators: generators}
 }
 
-func (p *Parser) parseExpressionStatement() *ASTNode {
-	expr := p.parseExpression()
-	if expr == nil {
+// parseTargetList analiza los destinos de un for, como "x" o "i, (a, b)",
+// sin consumir el 'in' que les sigue.
+func (p *Parser) parseTargetList() Expr {
+	defer p.rule("star_targets")()
+	start := tokenPosition(p.peek())
+	first := p.parseStarred(p.parseBitOr)
+	if first == nil {
 		return nil
 	}
 	
-	return &ASTNode{
-		Type:     "ExpressionStatement",
-		Line:     expr.Line,
-		Children: []*ASTNode{expr},
+	target := first
+	if p.check(",") {
+		tuple := &Tuple{Elts: []Expr{first}}
+		for p.match(",") && !p.check("in") {
+			element := p.parseStarred(p.parseBitOr)
+			if element == nil {
+				return nil
+			}
+			tuple.Elts = append(tuple.Elts, element)
+		}
+		tuple.Position = p.spanFrom(start)
+		target = tuple
+	}
+	
+	if p.violated(starredViolation(target)) || p.violated(targetViolation(target)) {
+		return nil
 	}
+	return target
 }
 
-func (p *Parser) parseExpression() *ASTNode {
-	return p.parseComparison()
+// parseStarTarget analiza un único destino, como el de "with a as b".
+func (p *Parser) parseStarTarget() Expr {
+	defer p.rule("star_target")()
+	return p.parseStarred(p.parseBitOr)
 }
 
-func (p *Parser) parseComparison() *ASTNode {
-	expr := p.parseTerm()
+// parseDictOrSet analiza un diccionario o un conjunto. "{}" es un
+// diccionario vacío; el primer elemento decide el tipo de la colección.
+func (p *Parser) parseDictOrSet() Expr {
+	start := tokenPosition(p.previous())
 	
-	for p.match(">", "<", ">=", "<=", "==", "!=") {
-		operator := p.previous().Value
-		right := p.parseTerm()
-		expr = &ASTNode{
-			Type:     "BinaryOp",
-			Value:    operator,
-			Line:     expr.Line,
-			Children: []*ASTNode{expr, right},
-		}
+	if p.match("}") {
+		return &Dict{Position: p.spanFrom(start)}
 	}
 	
-	return expr
-}
-
-func (p *Parser) parseTerm() *ASTNode {
-	expr := p.parseFactor()
+	if p.check("**") {
+		return p.parseDictEntries(&Dict{Position: start})
+	}
 	
-	for p.match("+", "-") {
-		operator := p.previous().Value
-		right := p.parseFactor()
-		expr = &ASTNode{
-			Type:     "BinaryOp",
-			Value:    operator,
-			Line:     expr.Line,
-			Children: []*ASTNode{expr, right},
-		}
+	first := p.parseStarNamedExpression()
+	if first == nil {
+		return nil
 	}
 	
-	return expr
-}
-
-func (p *Parser) parseFactor() *ASTNode {
-	if p.match("(") {
-		expr := p.parseExpression()
-		if !p.match(")") {
-			p.error("Se esperaba ')' después de la expresión")
+	_, starred := first.(*Starred)
+	_, named := first.(*NamedExpr)
+	if !starred && !named && p.match(":") {
+		value := p.parseExpression()
+		if value == nil {
+			return nil
 		}
-		return expr
+		if p.checkComprehension() {
+			return p.parseComprehension("DictComp", start, []Expr{first, value}, "}")
+		}
+		dict := &Dict{
+			Position: start,
+			Keys:     []Expr{first},
+			Values:   []Expr{value},
+		}
+		return p.parseDictEntries(dict)
 	}
 	
-	if p.checkType(lexer.NUMBER) {
-		return &ASTNode{
-			Type:  "Number",
-			Value: p.advance().Value,
-			Line:  p.previous().Line,
-		}
+	if p.checkComprehension() {
+		return p.parseComprehension("SetComp", start, []Expr{first}, "}")
 	}
 	
-	if p.checkType(lexer.STRING) {
-		return &ASTNode{
-			Type:  "String",
-			Value: p.advance().Value,
-			Line:  p.previous().Line,
+	elements := []Expr{first}
+	if p.match(",") {
+		elements = p.parseElements(elements, "}")
+		if elements == nil {
+			return nil
 		}
+	} else if !p.match("}") {
+		p.errorExpected("}", "Se esperaba '}' al final del conjunto")
+		return nil
 	}
 	
-	if p.checkType(lexer.IDENTIFIER) {
-		name := p.advance().Value
-		
-		// Verificar si es una llamada a función
-		if p.match("(") {
-			args := []*ASTNode{}
-			if !p.check(")") {
-				for {
-					arg := p.parseExpression()
-					if arg != nil {
-						args = append(args, arg)
-					}
-					if !p.match(",") {
-						break
-					}
-				}
-			}
-			
-			if !p.match(")") {
-				p.error("Se esperaba ')' después de los argumentos")
-			}
-			
-			return &ASTNode{
-				Type:     "FunctionCall",
-				Value:    name,
-				Line:     p.previous().Line,
-				Children: args,
-			}
-		}
-		
-		// Verificar acceso a atributo/método
-		if p.match(".") {
-			if !p.checkType(lexer.IDENTIFIER) {
-				p.error("Se esperaba nombre de método después de '.'")
+	return &Set{Position: p.spanFrom(start), Elts: elements}
+}
+
+// parseDictEntries continúa un diccionario a partir de las entradas ya
+// leídas y consume la '}' final. La posición del diccionario empieza en su
+// '{'.
+func (p *Parser) parseDictEntries(dict *Dict) Expr {
+	if len(dict.Keys) == 0 || p.match(",") {
+		for !p.check("}") {
+			key, value, ok := p.parseDictEntry()
+			if !ok {
 				return nil
 			}
-			
-			method := p.advance().Value
-			
-			if p.match("(") {
-				args := []*ASTNode{}
-				if !p.check(")") {
-					for {
-						arg := p.parseExpression()
-						if arg != nil {
-							args = append(args, arg)
-						}
-						if !p.match(",") {
-							break
-						}
-					}
-				}
-				
-				if !p.match(")") {
-					p.error("Se esperaba ')' después de los argumentos del método")
-				}
-				
-				return &ASTNode{
-					Type:  "MethodCall",
-					Value: fmt.Sprintf("%s.%s", name, method),
-					Line:  p.previous().Line,
-					Children: args,
-				}
+			dict.Keys = append(dict.Keys, key)
+			dict.Values = append(dict.Values, value)
+			if !p.match(",") {
+				break
 			}
 		}
-		
-		return &ASTNode{
-			Type:  "Identifier",
-			Value: name,
-			Line:  p.previous().Line,
+	}
+	
+	if !p.match("}") {
+		p.errorExpected("}", "Se esperaba '}' al final del diccionario")
+		return nil
+	}
+	
+	dict.Position = p.spanFrom(dict.Position)
+	return dict
+}
+
+// parseDictEntry analiza "clave: valor" o "**otro" dentro de un diccionario.
+// En el segundo caso la clave es nil.
+func (p *Parser) parseDictEntry() (Expr, Expr, bool) {
+	if p.match("**") {
+		value := p.parseExpression()
+		return nil, value, value != nil
+	}
+	
+	key := p.parseExpression()
+	if key == nil {
+		return nil, nil, false
+	}
+	if !p.match(":") {
+		p.errorExpected(":", "Se esperaba ':' entre la clave y el valor del diccionario")
+		return nil, nil, false
+	}
+	value := p.parseExpression()
+	if value == nil {
+		return nil, nil, false
+	}
+	
+	return key, value, true
+}
+
+// parseElements agrega elementos separados por comas hasta el cierre
+// indicado, que se consume. Admite una coma final.
+func (p *Parser) parseElements(elements []Expr, closing string) []Expr {
+	for !p.check(closing) {
+		element := p.parseStarNamedExpression()
+		if element == nil {
+			return nil
+		}
+		elements = append(elements, element)
+		if !p.match(",") {
+			break
 		}
 	}
 	
-	p.error("Se esperaba expresión")
-	return nil
+	if !p.match(closing) {
+		p.errorExpected(closing, fmt.Sprintf("Se esperaba '%s' al final de la colección", closing))
+		return nil
+	}
+	return elements
 }
 
 // Métodos auxiliares
@@ -411,6 +2448,14 @@ func (p *Parser) checkType(tokenType lexer.TokenType) bool {
 	return p.peek().Type == tokenType
 }
 
+func (p *Parser) matchType(tokenType lexer.TokenType) bool {
+	if p.checkType(tokenType) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
 func (p *Parser) checkNext(tokenValue string) bool {
 	if p.current + 1 >= len(p.tokens) {
 		return false
@@ -418,13 +2463,48 @@ func (p *Parser) checkNext(tokenValue string) bool {
 	return p.tokens[p.current + 1].Value == tokenValue
 }
 
+// afterGroup devuelve el token que sigue al paréntesis, corchete o llave que
+// cierra el grupo abierto en la posición actual.
+func (p *Parser) afterGroup() lexer.Token {
+	depth := 0
+	for i := p.current; i < len(p.tokens); i++ {
+		switch p.tokens[i].Value {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				if i+1 < len(p.tokens) {
+					return p.tokens[i+1]
+				}
+				return lexer.Token{}
+			}
+		}
+	}
+	return lexer.Token{}
+}
+
 func (p *Parser) advance() lexer.Token {
 	if !p.isAtEnd() {
+		p.recordToken(p.peek())
 		p.current++
 	}
 	return p.previous()
 }
 
+// isAtExpressionEnd indica si el token actual no puede iniciar otra
+// expresión de una lista, por ejemplo tras la coma final de "x = 1, 2,".
+func (p *Parser) isAtExpressionEnd() bool {
+	if p.isAtEnd() || p.checkType(lexer.NEWLINE) || p.checkType(lexer.DEDENT) {
+		return true
+	}
+	switch p.peek().Value {
+	case "=", ")", "]", "}", ":":
+		return true
+	}
+	return false
+}
+
 func (p *Parser) isAtEnd() bool {
 	return p.current >= len(p.tokens)
 }
@@ -443,24 +2523,202 @@ func (p *Parser) previous() lexer.Token {
 	return p.tokens[p.current-1]
 }
 
-func (p *Parser) error(message string) {
-	line := 1
-	if !p.isAtEnd() {
-		line = p.peek().Line
+// continuesBracket indica si la línea que empieza en tokens[i] sigue dentro
+// de un paréntesis abierto en una línea anterior: lo cierra o termina en
+// ',', como un argumento más.
+func continuesBracket(tokens []lexer.Token, i int) bool {
+	balance := 0
+	last := ""
+	for _, token := range tokens[i:] {
+		if token.Line != tokens[i].Line {
+			break
+		}
+		if token.Type == lexer.WHITESPACE || token.Type == lexer.NEWLINE {
+			continue
+		}
+		switch token.Value {
+		case "(", "[", "{":
+			balance++
+		case ")", "]", "}":
+			balance--
+		}
+		if balance < 0 {
+			return true
+		}
+		last = token.Value
+	}
+	return last == ","
+}
+
+// opensBlock indica si la línea de tokens[i] es el encabezado completo de
+// una sentencia compuesta: sus paréntesis se cierran en la misma línea y
+// termina en ':'.
+func opensBlock(tokens []lexer.Token, i int) bool {
+	balance := 0
+	last := ""
+	for _, token := range tokens[i:] {
+		if token.Line != tokens[i].Line {
+			break
+		}
+		if token.Type == lexer.WHITESPACE || token.Type == lexer.NEWLINE {
+			continue
+		}
+		switch token.Value {
+		case "(", "[", "{":
+			balance++
+		case ")", "]", "}":
+			balance--
+		}
+		if balance < 0 {
+			return false
+		}
+		last = token.Value
+	}
+	return balance == 0 && last == ":"
+}
+
+// spanFrom ubica un nodo que empieza en start y termina en el último token
+// consumido, sin contar los saltos de línea ni la indentación que cierran
+// un bloque. Un nodo que no consumió tokens, como un bloque vacío, se queda
+// en start.
+func (p *Parser) spanFrom(start Position) Position {
+	for i := p.current - 1; i >= 0; i-- {
+		switch p.tokens[i].Type {
+		case lexer.NEWLINE, lexer.INDENT, lexer.DEDENT:
+			continue
+		}
+		end := tokenPosition(p.tokens[i])
+		if end.Line < start.Line || end.Line == start.Line && end.Column < start.Column {
+			return start
+		}
+		return through(start, end)
+	}
+	return start
+}
+
+// error registra un diagnóstico en el token actual, o al final del código
+// si ya no quedan tokens.
+func (p *Parser) error(code, message string) {
+	p.report(Diagnostic{Code: code, Message: message})
+}
+
+// errorExpected registra que falta un token concreto.
+func (p *Parser) errorExpected(expected, message string) {
+	p.report(Diagnostic{Code: ErrExpectedToken, Message: message, Expected: expected})
+}
+
+// errorAt registra un diagnóstico sobre toda la región de un nodo, como
+// el destino inválido de una asignación.
+func (p *Parser) errorAt(node Node, code, message string) {
+	p.report(Diagnostic{Code: code, Message: message, Span: node.Pos().Span()})
+}
+
+// violated registra la restricción incumplida, si la hay, e indica si lo
+// estaba.
+func (p *Parser) violated(v *violation) bool {
+	if v == nil {
+		return false
+	}
+	if v.node != nil {
+		p.errorAt(v.node, v.code, v.message)
+	} else {
+		p.error(v.code, v.message)
+	}
+	return true
+}
+
+func (p *Parser) report(diagnostic Diagnostic) {
+	if diagnostic.Span == (Span{}) {
+		token := p.peek()
+		if p.isAtEnd() {
+			token = p.endOfInput()
+		}
+		diagnostic.Found = tokenDescription(token)
+		diagnostic.Span = tokenSpan(token)
+	}
+	
+	p.errors = append(p.errors, diagnostic)
+	if p.panicMessage == "" {
+		p.panicMessage = diagnostic.Message
+	}
+}
+
+// endOfInput devuelve un token EOF ubicado justo después del último token.
+func (p *Parser) endOfInput() lexer.Token {
+	eof := lexer.Token{Type: lexer.EOF, Line: 1, Column: 1}
+	if len(p.tokens) > 0 {
+		last := p.tokens[len(p.tokens)-1]
+		eof.Line = last.Line
+		eof.Column = last.Column + len(last.Value)
 	}
-	p.errors = append(p.errors, fmt.Sprintf("Error en línea %d: %s", line, message))
+	return eof
 }
 
-func (p *Parser) getErrorLine() int {
-	if len(p.errors) == 0 {
-		return 0
+// tokenDescription nombra un token para los diagnósticos. Los tokens de
+// estructura no tienen texto, así que se usa su tipo.
+func tokenDescription(token lexer.Token) string {
+	switch token.Type {
+	case lexer.NEWLINE:
+		return "NEWLINE"
+	case lexer.INDENT:
+		return "INDENT"
+	case lexer.DEDENT:
+		return "DEDENT"
+	case lexer.EOF:
+		return "EOF"
 	}
-	// Extraer número de línea del primer error
-	errorMsg := p.errors[0]
-	if strings.Contains(errorMsg, "línea ") {
-		var line int
-		fmt.Sscanf(errorMsg, "Error en línea %d:", &line)
-		return line
+	return token.Value
+}
+
+func tokenSpan(token lexer.Token) Span {
+	return tokenPosition(token).Span()
+}
+
+// recover descarta el resto de una sentencia con error (modo pánico) y
+// devuelve un BadStmt que marca la región descartada. Los bloques
+// indentados y las cláusulas que la seguían se analizan igual para informar
+// sus propios errores.
+func (p *Parser) recover(start int) *BadStmt {
+	defer p.rule("error")()
+	bad := &BadStmt{Message: p.panicMessage}
+	from := tokenPosition(p.previous())
+	if start < len(p.tokens) {
+		from = tokenPosition(p.tokens[start])
+	}
+	p.panicMessage = ""
+	
+	// Garantiza avanzar aunque el error esté en el primer token
+	if p.current == start && !p.isAtEnd() {
+		p.advance()
+	}
+	p.synchronize()
+	
+	for {
+		if p.matchType(lexer.INDENT) {
+			bad.Blocks = append(bad.Blocks, p.parseIndentedBlock())
+		}
+		// else, elif, except y finally no inician una sentencia por sí solos
+		if !p.check("else") && !p.check("elif") && !p.check("except") && !p.check("finally") {
+			break
+		}
+		p.advance()
+		p.synchronize()
+	}
+	
+	bad.Position = p.spanFrom(from)
+	return bad
+}
+
+// synchronize avanza hasta el siguiente salto de línea o palabra que inicia
+// una sentencia, sin salir del bloque actual.
+func (p *Parser) synchronize() {
+	for !p.isAtEnd() && !p.checkType(lexer.DEDENT) {
+		if p.matchType(lexer.NEWLINE) {
+			return
+		}
+		if p.checkType(lexer.KEYWORD) && statementKeywords[p.peek().Value] {
+			return
+		}
+		p.advance()
 	}
-	return 0
 }
\ No newline at end of file
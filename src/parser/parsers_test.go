@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	
+	"examencorte2/src/lexer"
+)
+
+// validPrograms son programas válidos en los que los parsers deben decidir
+// entre dos producciones mirando más allá del próximo token o reconocer
+// match y case por su posición.
+var validPrograms = []string{
+	"with ():\n    pass\n",
+	"with () as x, (a):\n    pass\n",
+	"with (a, b):\n    pass\n",
+	"with (a, b) as c:\n    pass\n",
+	"with a as (b, c):\n    pass\n",
+	"with open(p) as self.f, q as r[0]:\n    pass\n",
+	"match x:\n    case 1: pass\n",
+	"match x:\n    case _:\n        match y:\n            case 2: pass\n        case = 3\n",
+}
+
+// invalidPrograms son programas que la gramática de src/gramatica acepta
+// pero que incumplen alguna de las restricciones de rules.go.
+var invalidPrograms = []string{
+	"1 = x\n",
+	"f() = 1\n",
+	"a = 1 = b\n",
+	"a + 1 += 2\n",
+	"(a, b) += 1\n",
+	"a, b: int\n",
+	"*a = 1\n",
+	"*a, *b = c\n",
+	"x = *a\n",
+	"(*a)\n",
+	"del 1\n",
+	"del (a, f())\n",
+	"for 1 in x: pass\n",
+	"for *a in b: pass\n",
+	"[x for 1 in y]\n",
+	"[*a for a in b]\n",
+	"{*a for a in b}\n",
+	"def f(/, a): pass\n",
+	"def f(a, /, /): pass\n",
+	"def f(*a, /): pass\n",
+	"def f(*, a, *b): pass\n",
+	"def f(**k, a): pass\n",
+	"def f(a=1, b): pass\n",
+	"def f(a, *): pass\n",
+	"def f(a, a): pass\n",
+	"lambda /: 1\n",
+	"lambda *: 1\n",
+	"f(a=1, a=2)\n",
+	"f(a=1, b)\n",
+	"f(**k, *a)\n",
+	"f(a, x for x in y)\n",
+	"f(x for x in y, 1)\n",
+	"try:\n    pass\n",
+	"try:\n    pass\nexcept:\n    pass\nexcept E:\n    pass\n",
+	"try:\n    pass\nelse:\n    pass\nfinally:\n    pass\n",
+	"match x:\n    case *a, *b:\n        pass\n",
+	"match x:\n    case [*a, *b]:\n        pass\n",
+	"match x:\n    case A(x=1, 2):\n        pass\n",
+	"match x:\n    case {**_}:\n        pass\n",
+	"match x:\n    case a as _:\n        pass\n",
+	"with a as *b:\n    pass\n",
+	"with a as f():\n    pass\n",
+}
+
+// TestParsersAgree verifica que Analyze, AnalyzeLL1 y AnalyzeLALR armen el
+// mismo árbol, con sus posiciones, para los programas de testdata/dump y
+// de validPrograms, y que los tres rechacen los de invalidPrograms.
+func TestParsersAgree(t *testing.T) {
+	sources, err := filepath.Glob(filepath.Join("testdata", "dump", "*.py"))
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("no se encontraron programas en testdata/dump: %v", err)
+	}
+	for _, source := range sources {
+		code, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareParsers(t, source, string(code), true)
+	}
+	for _, code := range validPrograms {
+		compareParsers(t, code, code, true)
+	}
+	for _, code := range invalidPrograms {
+		compareParsers(t, code, code, false)
+	}
+}
+
+func compareParsers(t *testing.T, name, code string, valid bool) {
+	tokens := lexer.Analyze(code).Tokens
+	want := Analyze(tokens)
+	if want.Success != valid {
+		t.Errorf("%q: Analyze Success = %v: %v", name, want.Success, want.Errors)
+		return
+	}
+	
+	options := DumpOptions{Attributes: true}
+	parsers := map[string]func([]lexer.Token) SyntaxResult{
+		"AnalyzeLL1":  AnalyzeLL1,
+		"AnalyzeLALR": AnalyzeLALR,
+	}
+	for parser, analyze := range parsers {
+		got := analyze(tokens)
+		if got.Success != valid {
+			t.Errorf("%q: %s Success = %v: %v", name, parser, got.Success, got.Errors)
+			continue
+		}
+		if valid && Dump(got.Module, options) != Dump(want.Module, options) {
+			t.Errorf("%q: el árbol de %s no coincide con el de Analyze:\n%s", name, parser, Dump(got.Module, options))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	
+	"examencorte2/src/lexer"
+)
+
+// TestRecoveryAfterUnclosedBracket verifica que un paréntesis sin cerrar no
+// oculte los errores de las sentencias que no pueden continuarlo, y que las
+// líneas que solo continúan un paréntesis abierto no se tomen por
+// sentencias nuevas.
+func TestRecoveryAfterUnclosedBracket(t *testing.T) {
+	tests := []struct {
+		code  string
+		lines []int
+	}{
+		{"x = foo(1\ny = 2 +\nz = 3 +\ndef g(:\n    pass\n", []int{2, 4}},
+		{"x = [1, 2\ny += 3\nprint(x\nif a:\n    b = {1:\n    c = 2\n", []int{2, 6}},
+		{"if a:\n    x = (1,\n    y = 2\nz = 3 +\nif b:\n    w = 4 +\n", []int{3, 6}},
+		{"def f(:\n    x = 1 +\ny = 2\n", []int{1, 2}},
+		{"if a:\n        x = 1\n    y = 2\n", []int{3}},
+		{"if a:\n        x = 1\n    y = 2\n    z = 3\nw = 4 +\n", []int{3, 5}},
+		{"foo(\n    a=1,\n)\nd = {\n\"a\": 1,\n}\nf(a,\nb)\n", nil},
+		{"if a:\n    z = f(x,\n    k=1)\n    w = [i\n    for i in y]\n", nil},
+		{"foo(\nx,\ny=1\n)\n", nil},
+		{"total = sum(\nx\nfor x in y\n)\n", nil},
+		{"def f(x:\n      int):\n    pass\n", nil},
+	}
+	
+	for _, test := range tests {
+		result := Analyze(lexer.Analyze(test.code).Tokens)
+		var lines []int
+		for _, diagnostic := range result.Errors {
+			lines = append(lines, diagnostic.Span.Line)
+		}
+		if !reflect.DeepEqual(lines, test.lines) {
+			t.Errorf("%q: errores en las líneas %v, se esperaban %v: %v", test.code, lines, test.lines, result.Errors)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package parser
+
+import "fmt"
+
+// Restricciones que la gramática de src/gramatica no expresa, como qué
+// puede recibir una asignación o en qué orden van los parámetros. El
+// descenso recursivo las verifica mientras analiza y treeBuilder sobre el
+// árbol de los parsers dirigidos por tabla, así que los tres rechazan los
+// mismos programas.
+
+// violation es una restricción incumplida. node es el nodo que la incumple;
+// sin él, el diagnóstico va en el token donde se detectó.
+type violation struct {
+	node    Node
+	code    string
+	message string
+}
+
+// targetViolation verifica que una expresión pueda recibir una asignación:
+// nombres, atributos, índices y tuplas o listas de ellos con a lo sumo un
+// elemento con '*'.
+func targetViolation(target Expr) *violation {
+	var elements []Expr
+	switch target := target.(type) {
+	case *Identifier, *Attribute, *Subscript:
+		return nil
+	case *Tuple:
+		elements = target.Elts
+	case *List:
+		elements = target.Elts
+	default:
+		return &violation{target, ErrInvalidTarget, fmt.Sprintf("No se puede asignar a %s", targetDescription(target))}
+	}
+	
+	starred := 0
+	for _, element := range elements {
+		if star, ok := element.(*Starred); ok {
+			starred++
+			element = star.Value
+		}
+		if v := targetViolation(element); v != nil {
+			return v
+		}
+	}
+	if starred > 1 {
+		return &violation{nil, ErrInvalidStarred, "Solo puede haber una expresión con '*' en el destino de una asignación"}
+	}
+	return nil
+}
+
+// deleteViolation verifica que se pueda eliminar el destino: nombres,
+// atributos, índices y tuplas o listas de ellos.
+func deleteViolation(target Expr) *violation {
+	var elements []Expr
+	switch target := target.(type) {
+	case *Identifier, *Attribute, *Subscript:
+		return nil
+	case *Tuple:
+		elements = target.Elts
+	case *List:
+		elements = target.Elts
+	default:
+		return &violation{target, ErrInvalidTarget, fmt.Sprintf("No se puede eliminar %s", targetDescription(target))}
+	}
+	
+	for _, element := range elements {
+		if v := deleteViolation(element); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// augAssignViolation y annAssignViolation verifican el destino de "x += 1"
+// y de "x: int", que debe ser un único nombre, atributo o índice.
+func augAssignViolation(operator string, target Expr) *violation {
+	if isSingleTarget(target) {
+		return nil
+	}
+	return &violation{target, ErrInvalidTarget, fmt.Sprintf("No se puede usar '%s' con %s", operator, targetDescription(target))}
+}
+
+func annAssignViolation(target Expr) *violation {
+	if isSingleTarget(target) {
+		return nil
+	}
+	return &violation{target, ErrInvalidTarget, fmt.Sprintf("No se puede anotar el tipo de %s", targetDescription(target))}
+}
+
+func isSingleTarget(target Expr) bool {
+	switch target.(type) {
+	case *Identifier, *Attribute, *Subscript:
+		return true
+	}
+	return false
+}
+
+func targetDescription(node Expr) string {
+	switch node.(type) {
+	case *Call:
+		return "una llamada a función"
+	case *Number, *String, *Boolean, *None, *Ellipsis:
+		return "un literal"
+	case *Tuple, *List:
+		return "una tupla o lista"
+	case *Dict, *Set:
+		return "un diccionario o conjunto"
+	case *BinaryOp, *UnaryOp:
+		return "una operación"
+	}
+	return "esta expresión"
+}
+
+// starredViolation rechaza un '*' suelto como "*a" o "(*a)": solo tiene
+// sentido dentro de una colección.
+func starredViolation(expr Expr) *violation {
+	if _, ok := expr.(*Starred); !ok {
+		return nil
+	}
+	return &violation{expr, ErrInvalidStarred, "No se puede usar una expresión con '*' fuera de una colección"}
+}
+
+// comprehensionViolation rechaza un elemento con '*' en una comprensión.
+func comprehensionViolation(elements []Expr) *violation {
+	for _, element := range elements {
+		if _, ok := element.(*Starred); ok {
+			return &violation{element, ErrInvalidStarred, "No se puede usar una expresión con '*' en una comprensión"}
+		}
+	}
+	return nil
+}
+
+// sequenceViolation verifica que un patrón de secuencia tenga a lo sumo un
+// patrón con '*'.
+func sequenceViolation(patterns []Pattern) *violation {
+	stars := 0
+	for _, pattern := range patterns {
+		if _, ok := pattern.(*MatchStar); ok {
+			stars++
+		}
+	}
+	if stars > 1 {
+		return &violation{nil, ErrInvalidPattern, "Un patrón de secuencia admite solo un '*'"}
+	}
+	return nil
+}
+
+// parameterRules sigue las restricciones de una lista de parámetros a
+// medida que se leen: '/' una sola vez, después de al menos un parámetro y
+// antes de '*'; un solo '*'; '**' al final; sin nombres repetidos ni
+// parámetros sin valor por defecto después de uno que lo tiene.
+type parameterRules struct {
+	seen        map[string]bool
+	kind        ParamKind
+	sawSlash    bool
+	sawDefault  bool
+	keywordOnly int
+}
+
+func newParameterRules() *parameterRules {
+	return &parameterRules{seen: map[string]bool{}, kind: RegularParam}
+}
+
+// slash verifica un '/' que sigue a params.
+func (r *parameterRules) slash(params []*Parameter) *violation {
+	if r.sawSlash || r.kind != RegularParam || len(params) == 0 {
+		return &violation{nil, ErrInvalidParameters, "'/' debe aparecer una sola vez, después de al menos un parámetro y antes de '*'"}
+	}
+	r.sawSlash = true
+	return nil
+}
+
+// star verifica un '*'; los parámetros que le siguen son solo por nombre.
+func (r *parameterRules) star() *violation {
+	if r.kind == KeywordOnlyParam {
+		return &violation{nil, ErrInvalidParameters, "Solo puede haber un '*' en la lista de parámetros"}
+	}
+	r.kind = KeywordOnlyParam
+	return nil
+}
+
+func (r *parameterRules) name(name string) *violation {
+	repeated := r.seen[name]
+	r.seen[name] = true
+	if repeated {
+		return &violation{nil, ErrInvalidParameters, fmt.Sprintf("Parámetro '%s' repetido en la definición", name)}
+	}
+	return nil
+}
+
+// kwargs verifica que '**param' sea el último parámetro.
+func (r *parameterRules) kwargs(param *Parameter, last bool) *violation {
+	if last {
+		return nil
+	}
+	return &violation{nil, ErrInvalidParameters, fmt.Sprintf("'**%s' debe ser el último parámetro", param.Name)}
+}
+
+// regular verifica un parámetro con nombre, con su valor por defecto ya
+// leído.
+func (r *parameterRules) regular(param *Parameter) *violation {
+	switch {
+	case r.kind == KeywordOnlyParam:
+		r.keywordOnly++
+	case param.Default != nil:
+		r.sawDefault = true
+	case r.sawDefault:
+		return &violation{nil, ErrInvalidParameters, fmt.Sprintf("El parámetro '%s' no tiene valor por defecto pero sigue a uno que sí lo tiene", param.Name)}
+	}
+	return nil
+}
+
+// end verifica la lista completa. "def f(a, *):" no tiene sentido: el '*'
+// solo debe separar parámetros.
+func (r *parameterRules) end(params []*Parameter) *violation {
+	if r.kind != KeywordOnlyParam || r.keywordOnly > 0 {
+		return nil
+	}
+	for _, param := range params {
+		if param.Kind == VarArgsParam {
+			return nil
+		}
+	}
+	return &violation{nil, ErrInvalidParameters, "Después de '*' debe haber al menos un parámetro con nombre"}
+}
+
+// argumentRules sigue las restricciones de los argumentos de una llamada:
+// sin nombres repetidos, sin posicionales después de uno por nombre y sin
+// '*' después de '**'.
+type argumentRules struct {
+	names         map[string]bool
+	keywords      int
+	sawDoubleStar bool
+}
+
+func newArgumentRules() *argumentRules {
+	return &argumentRules{names: map[string]bool{}}
+}
+
+// add verifica el próximo argumento: una expresión, un Starred o un
+// Keyword, que sin nombre es un '**'.
+func (r *argumentRules) add(arg Node) *violation {
+	keyword, isKeyword := arg.(*Keyword)
+	switch {
+	case isKeyword:
+		r.keywords++
+		if keyword.Name == "" {
+			r.sawDoubleStar = true
+			return nil
+		}
+		repeated := r.names[keyword.Name]
+		r.names[keyword.Name] = true
+		if repeated {
+			return &violation{keyword, ErrInvalidArguments, fmt.Sprintf("Argumento por nombre '%s' repetido", keyword.Name)}
+		}
+	default:
+		if _, starred := arg.(*Starred); starred {
+			if r.sawDoubleStar {
+				return &violation{nil, ErrInvalidArguments, "No se puede desempaquetar con '*' después de '**'"}
+			}
+		} else if r.keywords > 0 {
+			return &violation{nil, ErrInvalidArguments, "Argumento posicional después de un argumento por nombre"}
+		}
+	}
+	return nil
+}
+
+// generatorViolation rechaza f(a, x for x in datos): el generador sin
+// paréntesis debe ser el único argumento.
+func generatorViolation() *violation {
+	return &violation{nil, ErrInvalidArguments, "Un generador sin paréntesis debe ser el único argumento"}
+}
@@ -0,0 +1,10 @@
+a = b = 1
+a, b = b, a
+[x, *resto] = valores
+obj.attr = 1
+lista[0] = 2
+contador += 1
+bits <<= 2
+edad: int = 30
+nombre: str
+del a, lista[0], obj.attr
@@ -0,0 +1,6 @@
+print("hola", end="", *args, **kwargs)
+obj.metodo(1).otro[0]
+lista[1:2]
+lista[::2]
+matriz[i, j:k]
+total = sum(x * x for x in datos if x > 0)
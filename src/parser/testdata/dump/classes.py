@@ -0,0 +1,9 @@
+class Animal:
+    pass
+
+
+class Perro(Animal, metaclass=Meta):
+    nombre: str = "Firulais"
+
+    def ladrar(self):
+        print(self.nombre)
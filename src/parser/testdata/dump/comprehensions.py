@@ -0,0 +1,5 @@
+cuadrados = [x ** 2 for x in range(10) if x % 2 == 0]
+pares = {x for x in datos}
+indice = {k: v for k, v in pares if k if v}
+anidada = [(x, y) for x in a for y in b]
+generador = (x for x in datos)
@@ -0,0 +1,23 @@
+for i in range(10):
+    print(i)
+else:
+    print("fin")
+
+for clave, valor in datos.items():
+    continue_ = clave
+
+try:
+    riesgo()
+except ValueError as error:
+    raise RuntimeError("mal") from error
+except (KeyError, IndexError):
+    raise
+except:
+    pass
+else:
+    exito()
+finally:
+    limpiar()
+
+with open("a") as f, open("b") as g:
+    f.read()
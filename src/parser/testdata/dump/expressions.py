@@ -0,0 +1,11 @@
+x = (a + b) * c - d / e // f % g @ h
+y = -x ** 2 + ~z
+z = a << 1 | b >> 2 & c ^ d
+ok = not a and b or c
+mayor = a > b
+rango = 0 <= i < n
+pertenece = x not in xs and y is not None and z in zs
+valor = a if b else c
+f = lambda p, q=1: p + q
+if (n := len(xs)) > 10:
+    pass
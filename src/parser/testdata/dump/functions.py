@@ -0,0 +1,20 @@
+@decorador
+@otro.decorador(1)
+def suma(a, b: int = 0, *args, c, d=2, **kwargs) -> int:
+    global total
+    return_value = a + b
+    assert return_value > 0, "negativo"
+
+
+def solo(a, b, /, c, *, d):
+    def interna():
+        nonlocal a
+        a = 1
+
+
+async def tarea(x):
+    await x
+    async for item in x:
+        pass
+    async with bloqueo:
+        pass
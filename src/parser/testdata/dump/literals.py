@@ -0,0 +1,21 @@
+entero = 42
+grande = 123456789012345678901234567890
+flotante = 3.14
+corto = 1.
+exacto = 2.50
+enorme = 12345678901234567890.0
+texto = "hola"
+simples = 'dice "hola"'
+dobles = "it's"
+escapes = "a\tb\n\\c\x41é"
+desconocido = "\d"
+nada = None
+cierto = True
+falso = False
+puntos = ...
+lista = [1, 2, 3]
+tupla = (1, "dos", 3.0)
+vacia = ()
+uno = (1,)
+conjunto = {1, 2}
+diccionario = {"a": 1, **otro}
@@ -0,0 +1,21 @@
+match comando:
+    case 1 | 2:
+        pass
+    case -1:
+        pass
+    case None:
+        pass
+    case "salir" as texto:
+        pass
+    case [primero, *resto]:
+        pass
+    case [*_]:
+        pass
+    case {"clave": valor, **otros}:
+        pass
+    case Punto(0, y=0) if y > 0:
+        pass
+    case Color.ROJO:
+        pass
+    case _:
+        pass
@@ -0,0 +1,221 @@
+package parser
+
+// Transform reescribe el árbol de abajo hacia arriba y modifica los nodos en
+// el lugar. f recibe cada nodo con sus hijos ya transformados y devuelve el
+// nodo que ocupa su lugar. Si f devuelve nil o un nodo que no cabe en esa
+// posición (una sentencia donde va una expresión, por ejemplo) se conserva el
+// original, salvo en los cuerpos de sentencias, donde nil elimina la
+// sentencia.
+func Transform(node Node, f func(Node) Node) Node {
+	return transform(f, node)
+}
+
+func transform[T Node](f func(Node) Node, node T) T {
+	if isNil(node) {
+		return node
+	}
+	transformChildren(f, node)
+	if replaced, ok := f(node).(T); ok {
+		return replaced
+	}
+	return node
+}
+
+func transformList[T Node](f func(Node) Node, list []T) []T {
+	for i, node := range list {
+		list[i] = transform(f, node)
+	}
+	return list
+}
+
+func transformStmts(f func(Node) Node, body []Stmt) []Stmt {
+	result := body[:0]
+	for _, stmt := range body {
+		if isNil(stmt) {
+			result = append(result, stmt)
+			continue
+		}
+		transformChildren(f, stmt)
+		replaced := f(stmt)
+		if replaced == nil {
+			continue
+		}
+		if s, ok := replaced.(Stmt); ok {
+			stmt = s
+		}
+		result = append(result, stmt)
+	}
+	return result
+}
+
+// transformChildren transforma los hijos de un nodo en el mismo orden que
+// los devuelve Children.
+func transformChildren(f func(Node) Node, node Node) {
+	switch n := node.(type) {
+	case *Module:
+		n.Body = transformStmts(f, n.Body)
+	case *Block:
+		n.Body = transformStmts(f, n.Body)
+	case *BadStmt:
+		n.Blocks = transformList(f, n.Blocks)
+		
+	case *FunctionDef:
+		n.Decorators = transformList(f, n.Decorators)
+		n.Params = transformList(f, n.Params)
+		n.Returns = transform(f, n.Returns)
+		n.Body = transform(f, n.Body)
+	case *Decorator:
+		n.Expr = transform(f, n.Expr)
+	case *Parameter:
+		n.Annotation = transform(f, n.Annotation)
+		n.Default = transform(f, n.Default)
+	case *ClassDef:
+		n.Decorators = transformList(f, n.Decorators)
+		n.Bases = transformList(f, n.Bases)
+		n.Keywords = transformList(f, n.Keywords)
+		n.Body = transform(f, n.Body)
+	case *If:
+		n.Test = transform(f, n.Test)
+		n.Body = transform(f, n.Body)
+	case *For:
+		n.Target = transform(f, n.Target)
+		n.Iter = transform(f, n.Iter)
+		n.Body = transform(f, n.Body)
+		n.Else = transform(f, n.Else)
+	case *Clause:
+		n.Body = transform(f, n.Body)
+	case *Try:
+		n.Body = transform(f, n.Body)
+		n.Handlers = transformList(f, n.Handlers)
+		n.Else = transform(f, n.Else)
+		n.Finally = transform(f, n.Finally)
+	case *ExceptHandler:
+		n.Type = transform(f, n.Type)
+		n.Body = transform(f, n.Body)
+	case *Raise:
+		n.Exc = transform(f, n.Exc)
+		n.Cause = transform(f, n.Cause)
+	case *With:
+		n.Items = transformList(f, n.Items)
+		n.Body = transform(f, n.Body)
+	case *WithItem:
+		n.Context = transform(f, n.Context)
+		n.Target = transform(f, n.Target)
+	case *Match:
+		n.Subject = transform(f, n.Subject)
+		n.Cases = transformList(f, n.Cases)
+	case *MatchCase:
+		n.Pattern = transform(f, n.Pattern)
+		n.Guard = transform(f, n.Guard)
+		n.Body = transform(f, n.Body)
+	case *Assign:
+		n.Targets = transformList(f, n.Targets)
+		n.Value = transform(f, n.Value)
+	case *AugAssign:
+		n.Target = transform(f, n.Target)
+		n.Value = transform(f, n.Value)
+	case *AnnAssign:
+		n.Target = transform(f, n.Target)
+		n.Annotation = transform(f, n.Annotation)
+		n.Value = transform(f, n.Value)
+	case *ExprStmt:
+		n.Value = transform(f, n.Value)
+	case *Global:
+		n.Names = transformList(f, n.Names)
+	case *Nonlocal:
+		n.Names = transformList(f, n.Names)
+	case *Delete:
+		n.Targets = transformList(f, n.Targets)
+	case *Assert:
+		n.Test = transform(f, n.Test)
+		n.Msg = transform(f, n.Msg)
+		
+	case *List:
+		n.Elts = transformList(f, n.Elts)
+	case *Tuple:
+		n.Elts = transformList(f, n.Elts)
+	case *Set:
+		n.Elts = transformList(f, n.Elts)
+	case *Dict:
+		for i := range n.Keys {
+			n.Keys[i] = transform(f, n.Keys[i])
+			n.Values[i] = transform(f, n.Values[i])
+		}
+	case *Starred:
+		n.Value = transform(f, n.Value)
+	case *Subscript:
+		n.Value = transform(f, n.Value)
+		n.Index = transform(f, n.Index)
+	case *Slice:
+		n.Lower = transform(f, n.Lower)
+		n.Upper = transform(f, n.Upper)
+		n.Step = transform(f, n.Step)
+	case *Attribute:
+		n.Value = transform(f, n.Value)
+	case *Call:
+		n.Func = transform(f, n.Func)
+		n.Args = transformList(f, n.Args)
+		n.Keywords = transformList(f, n.Keywords)
+	case *Keyword:
+		n.Value = transform(f, n.Value)
+	case *UnaryOp:
+		n.Operand = transform(f, n.Operand)
+	case *BinaryOp:
+		n.Left = transform(f, n.Left)
+		n.Right = transform(f, n.Right)
+	case *Compare:
+		n.Left = transform(f, n.Left)
+		n.Comparators = transformList(f, n.Comparators)
+	case *BoolOp:
+		n.Values = transformList(f, n.Values)
+	case *IfExp:
+		n.Body = transform(f, n.Body)
+		n.Test = transform(f, n.Test)
+		n.Orelse = transform(f, n.Orelse)
+	case *Lambda:
+		n.Params = transformList(f, n.Params)
+		n.Body = transform(f, n.Body)
+	case *NamedExpr:
+		n.Target = transform(f, n.Target)
+		n.Value = transform(f, n.Value)
+	case *ListComp:
+		n.Elt = transform(f, n.Elt)
+		n.Generators = transformList(f, n.Generators)
+	case *SetComp:
+		n.Elt = transform(f, n.Elt)
+		n.Generators = transformList(f, n.Generators)
+	case *GeneratorExp:
+		n.Elt = transform(f, n.Elt)
+		n.Generators = transformList(f, n.Generators)
+	case *DictComp:
+		n.Key = transform(f, n.Key)
+		n.Value = transform(f, n.Value)
+		n.Generators = transformList(f, n.Generators)
+	case *Comprehension:
+		n.Target = transform(f, n.Target)
+		n.Iter = transform(f, n.Iter)
+		n.Ifs = transformList(f, n.Ifs)
+	case *Await:
+		n.Value = transform(f, n.Value)
+		
+	case *MatchValue:
+		n.Value = transform(f, n.Value)
+	case *MatchSequence:
+		n.Patterns = transformList(f, n.Patterns)
+	case *MatchMapping:
+		for i := range n.Keys {
+			n.Keys[i] = transform(f, n.Keys[i])
+			n.Patterns[i] = transform(f, n.Patterns[i])
+		}
+	case *MatchClass:
+		n.Cls = transform(f, n.Cls)
+		n.Patterns = transformList(f, n.Patterns)
+		n.Keywords = transformList(f, n.Keywords)
+	case *MatchKeyword:
+		n.Pattern = transform(f, n.Pattern)
+	case *MatchAs:
+		n.Pattern = transform(f, n.Pattern)
+	case *MatchOr:
+		n.Patterns = transformList(f, n.Patterns)
+	}
+}
@@ -0,0 +1,555 @@
+package parser
+
+import "strings"
+
+// Precedencia de las expresiones, de menor a mayor. Una subexpresión va
+// entre paréntesis cuando su precedencia es menor que la que exige la
+// posición donde aparece.
+const (
+	precNamed = iota
+	precTuple
+	precTest
+	precOr
+	precAnd
+	precNot
+	precCompare
+	precBitOr
+	precBitXor
+	precBitAnd
+	precShift
+	precArith
+	precTerm
+	precFactor
+	precPower
+	precAwait
+	precAtom
+)
+
+var binaryPrecedence = map[string]int{
+	"|": precBitOr, "^": precBitXor, "&": precBitAnd,
+	"<<": precShift, ">>": precShift,
+	"+": precArith, "-": precArith,
+	"*": precTerm, "/": precTerm, "//": precTerm, "%": precTerm, "@": precTerm,
+	"**": precPower,
+}
+
+// Unparse genera código Python normalizado a partir de un nodo: indentación
+// de cuatro espacios, un espacio alrededor de los operadores y solo los
+// paréntesis que exige la precedencia. Las sentencias con error se
+// reemplazan por pass y un comentario con el mensaje. Sin árbol devuelve "".
+func Unparse(node Node) string {
+	if isNil(node) {
+		return ""
+	}
+	switch n := node.(type) {
+	case Expr:
+		return unparseExpr(n, precTest)
+	case Pattern:
+		return unparsePattern(n)
+	}
+	
+	u := &unparser{}
+	switch n := node.(type) {
+	case *Module:
+		u.stmts(n.Body)
+	case *Block:
+		u.stmts(n.Body)
+	case Stmt:
+		u.stmt(n)
+	}
+	return strings.Join(u.lines, "\n")
+}
+
+type unparser struct {
+	lines  []string
+	indent int
+}
+
+func (u *unparser) line(text string) {
+	u.lines = append(u.lines, strings.Repeat("    ", u.indent)+text)
+}
+
+func (u *unparser) stmts(body []Stmt) {
+	for _, stmt := range body {
+		u.stmt(stmt)
+	}
+}
+
+// block escribe la cabecera de una sentencia compuesta y su cuerpo
+// indentado. Un cuerpo vacío se escribe como pass.
+func (u *unparser) block(header string, body *Block) {
+	u.line(header + ":")
+	u.indent++
+	if len(body.Body) == 0 {
+		u.line("pass")
+	}
+	u.stmts(body.Body)
+	u.indent--
+}
+
+func (u *unparser) clause(header string, c *Clause) {
+	if c != nil {
+		u.block(header, c.Body)
+	}
+}
+
+func (u *unparser) stmt(stmt Stmt) {
+	switch n := stmt.(type) {
+	case *Block:
+		// Indentación inesperada: el bloque queda al nivel actual
+		u.stmts(n.Body)
+	case *BadStmt:
+		// pass mantiene válido un bloque cuya única sentencia tenía error
+		u.line("pass  # Error: " + n.Message)
+		
+	case *FunctionDef:
+		for _, decorator := range n.Decorators {
+			u.line("@" + unparseExpr(decorator.Expr, precTest))
+		}
+		header := "def " + n.Name + "(" + unparseParams(n.Params) + ")"
+		if n.Async {
+			header = "async " + header
+		}
+		if n.Returns != nil {
+			header += " -> " + unparseExpr(n.Returns, precTest)
+		}
+		u.block(header, n.Body)
+	case *ClassDef:
+		for _, decorator := range n.Decorators {
+			u.line("@" + unparseExpr(decorator.Expr, precTest))
+		}
+		header := "class " + n.Name
+		if arguments := unparseArguments(n.Bases, n.Keywords); arguments != "" {
+			header += "(" + arguments + ")"
+		}
+		u.block(header, n.Body)
+	case *If:
+		u.block("if "+unparseExpr(n.Test, precTest), n.Body)
+	case *For:
+		header := "for " + unparseExpr(n.Target, precTuple) + " in " + unparseExpr(n.Iter, precTuple)
+		if n.Async {
+			header = "async " + header
+		}
+		u.block(header, n.Body)
+		u.clause("else", n.Else)
+	case *Try:
+		u.block("try", n.Body)
+		for _, handler := range n.Handlers {
+			header := "except"
+			if handler.Type != nil {
+				header += " " + unparseExpr(handler.Type, precTest)
+			}
+			if handler.Name != "" {
+				header += " as " + handler.Name
+			}
+			u.block(header, handler.Body)
+		}
+		u.clause("else", n.Else)
+		u.clause("finally", n.Finally)
+	case *With:
+		items := []string{}
+		for _, item := range n.Items {
+			text := unparseExpr(item.Context, precTest)
+			if item.Target != nil {
+				text += " as " + unparseExpr(item.Target, precBitOr)
+			}
+			items = append(items, text)
+		}
+		header := "with " + strings.Join(items, ", ")
+		if n.Async {
+			header = "async " + header
+		}
+		u.block(header, n.Body)
+	case *Match:
+		u.line("match " + unparseExpr(n.Subject, precTuple) + ":")
+		u.indent++
+		for _, matchCase := range n.Cases {
+			header := "case " + unparsePattern(matchCase.Pattern)
+			if matchCase.Guard != nil {
+				header += " if " + unparseExpr(matchCase.Guard, precTest)
+			}
+			u.block(header, matchCase.Body)
+		}
+		u.indent--
+		
+	case *Assign:
+		parts := []string{}
+		for _, target := range n.Targets {
+			parts = append(parts, unparseExpr(target, precTuple))
+		}
+		parts = append(parts, unparseExpr(n.Value, precTuple))
+		u.line(strings.Join(parts, " = "))
+	case *AugAssign:
+		u.line(unparseExpr(n.Target, precTuple) + " " + n.Op + " " + unparseExpr(n.Value, precTuple))
+	case *AnnAssign:
+		text := unparseExpr(n.Target, precTuple) + ": " + unparseExpr(n.Annotation, precTest)
+		if n.Value != nil {
+			text += " = " + unparseExpr(n.Value, precTuple)
+		}
+		u.line(text)
+	case *ExprStmt:
+		u.line(unparseExpr(n.Value, precTuple))
+	case *Pass:
+		u.line("pass")
+	case *Global:
+		u.line("global " + joinNames(n.Names))
+	case *Nonlocal:
+		u.line("nonlocal " + joinNames(n.Names))
+	case *Delete:
+		u.line("del " + unparseExprs(n.Targets, precBitOr))
+	case *Assert:
+		text := "assert " + unparseExpr(n.Test, precTest)
+		if n.Msg != nil {
+			text += ", " + unparseExpr(n.Msg, precTest)
+		}
+		u.line(text)
+	case *Raise:
+		text := "raise"
+		if n.Exc != nil {
+			text += " " + unparseExpr(n.Exc, precTest)
+		}
+		if n.Cause != nil {
+			text += " from " + unparseExpr(n.Cause, precTest)
+		}
+		u.line(text)
+	}
+}
+
+func joinNames(names []*Identifier) string {
+	parts := []string{}
+	for _, name := range names {
+		parts = append(parts, name.Name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unparseParams escribe una lista de parámetros con los separadores '/' y
+// '*' que indican los parámetros solo posicionales y solo por nombre.
+func unparseParams(params []*Parameter) string {
+	parts := []string{}
+	sawVarArgs := false
+	for i, param := range params {
+		if param.Kind == KeywordOnlyParam && !sawVarArgs {
+			parts = append(parts, "*")
+			sawVarArgs = true
+		}
+		
+		text := param.Name
+		switch param.Kind {
+		case VarArgsParam:
+			text = "*" + text
+			sawVarArgs = true
+		case KwArgsParam:
+			text = "**" + text
+		}
+		if param.Annotation != nil {
+			text += ": " + unparseExpr(param.Annotation, precTest)
+		}
+		if param.Default != nil {
+			if param.Annotation != nil {
+				text += " = " + unparseExpr(param.Default, precTest)
+			} else {
+				text += "=" + unparseExpr(param.Default, precTest)
+			}
+		}
+		parts = append(parts, text)
+		
+		last := i == len(params)-1
+		if param.Kind == PositionalOnlyParam && (last || params[i+1].Kind != PositionalOnlyParam) {
+			parts = append(parts, "/")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func unparseArguments(args []Expr, keywords []*Keyword) string {
+	parts := []string{}
+	for _, arg := range args {
+		parts = append(parts, unparseExpr(arg, precTest))
+	}
+	for _, keyword := range keywords {
+		if keyword.Name == "" {
+			parts = append(parts, "**"+unparseExpr(keyword.Value, precTest))
+		} else {
+			parts = append(parts, keyword.Name+"="+unparseExpr(keyword.Value, precTest))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func unparseExprs(list []Expr, prec int) string {
+	parts := []string{}
+	for _, expr := range list {
+		parts = append(parts, unparseExpr(expr, prec))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// precedence devuelve la precedencia de una expresión.
+func precedence(expr Expr) int {
+	switch n := expr.(type) {
+	case *NamedExpr:
+		return precNamed
+	case *Tuple:
+		// La tupla vacía y la de un elemento siempre llevan paréntesis
+		if len(n.Elts) < 2 {
+			return precAtom
+		}
+		return precTuple
+	case *Lambda, *IfExp:
+		return precTest
+	case *BoolOp:
+		if n.Op == "or" {
+			return precOr
+		}
+		return precAnd
+	case *UnaryOp:
+		if n.Op == "not" {
+			return precNot
+		}
+		return precFactor
+	case *Compare:
+		return precCompare
+	case *BinaryOp:
+		if prec, ok := binaryPrecedence[n.Op]; ok {
+			return prec
+		}
+		return precCompare
+	case *Await:
+		return precAwait
+	}
+	return precAtom
+}
+
+// unparseExpr escribe una expresión en una posición que exige la
+// precedencia prec, agregando paréntesis si hace falta.
+func unparseExpr(expr Expr, prec int) string {
+	text := unparseBare(expr)
+	if precedence(expr) < prec {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+func unparseBare(expr Expr) string {
+	switch n := expr.(type) {
+	case *Identifier:
+		return n.Name
+	case *Number:
+		return n.Value
+	case *String:
+		return n.Value
+	case *Boolean:
+		if n.Value {
+			return "True"
+		}
+		return "False"
+	case *None:
+		return "None"
+	case *Ellipsis:
+		return "..."
+		
+	case *List:
+		return "[" + unparseExprs(n.Elts, precTest) + "]"
+	case *Tuple:
+		switch len(n.Elts) {
+		case 0:
+			return "()"
+		case 1:
+			return "(" + unparseExpr(n.Elts[0], precTest) + ",)"
+		}
+		return unparseExprs(n.Elts, precTest)
+	case *Set:
+		if len(n.Elts) == 0 {
+			return "set()"
+		}
+		return "{" + unparseExprs(n.Elts, precTest) + "}"
+	case *Dict:
+		parts := []string{}
+		for i, key := range n.Keys {
+			if key == nil {
+				parts = append(parts, "**"+unparseExpr(n.Values[i], precBitOr))
+			} else {
+				parts = append(parts, unparseExpr(key, precTest)+": "+unparseExpr(n.Values[i], precTest))
+			}
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case *Starred:
+		return "*" + unparseExpr(n.Value, precBitOr)
+		
+	case *Subscript:
+		return unparseExpr(n.Value, precAtom) + "[" + unparseExpr(n.Index, precTuple) + "]"
+	case *Slice:
+		text := optionalExpr(n.Lower) + ":" + optionalExpr(n.Upper)
+		if n.Step != nil {
+			text += ":" + unparseExpr(n.Step, precTest)
+		}
+		return text
+	case *Attribute:
+		value := unparseExpr(n.Value, precAtom)
+		// 1.real se leería como el número "1." seguido de un nombre
+		if number, ok := n.Value.(*Number); ok && !strings.Contains(number.Value, ".") {
+			value = "(" + value + ")"
+		}
+		return value + "." + n.Attr
+	case *Call:
+		// f(x for x in datos) no necesita paréntesis extra
+		if len(n.Args) == 1 && len(n.Keywords) == 0 {
+			if generator, ok := n.Args[0].(*GeneratorExp); ok {
+				return unparseExpr(n.Func, precAtom) + "(" + unparseComprehension(generator.Elt, nil, generator.Generators) + ")"
+			}
+		}
+		return unparseExpr(n.Func, precAtom) + "(" + unparseArguments(n.Args, n.Keywords) + ")"
+		
+	case *UnaryOp:
+		if n.Op == "not" {
+			return "not " + unparseExpr(n.Operand, precNot)
+		}
+		return n.Op + unparseExpr(n.Operand, precFactor)
+	case *BinaryOp:
+		prec := precedence(n)
+		switch {
+		case n.Op == "**":
+			// Asociativo por la derecha: 2 ** 3 ** 2 es 2 ** (3 ** 2)
+			return unparseExpr(n.Left, precAwait) + " ** " + unparseExpr(n.Right, precFactor)
+		case prec == precCompare:
+			return unparseExpr(n.Left, prec+1) + " " + n.Op + " " + unparseExpr(n.Right, prec+1)
+		}
+		return unparseExpr(n.Left, prec) + " " + n.Op + " " + unparseExpr(n.Right, prec+1)
+	case *Compare:
+		text := unparseExpr(n.Left, precCompare+1)
+		for i, operator := range n.Ops {
+			text += " " + operator + " " + unparseExpr(n.Comparators[i], precCompare+1)
+		}
+		return text
+	case *BoolOp:
+		prec := precedence(n)
+		parts := []string{}
+		for _, value := range n.Values {
+			parts = append(parts, unparseExpr(value, prec+1))
+		}
+		return strings.Join(parts, " "+n.Op+" ")
+	case *IfExp:
+		return unparseExpr(n.Body, precOr) + " if " + unparseExpr(n.Test, precOr) + " else " + unparseExpr(n.Orelse, precTest)
+	case *Lambda:
+		if len(n.Params) == 0 {
+			return "lambda: " + unparseExpr(n.Body, precTest)
+		}
+		return "lambda " + unparseParams(n.Params) + ": " + unparseExpr(n.Body, precTest)
+	case *NamedExpr:
+		return n.Target.Name + " := " + unparseExpr(n.Value, precTest)
+	case *Await:
+		return "await " + unparseExpr(n.Value, precAtom)
+		
+	case *ListComp:
+		return "[" + unparseComprehension(n.Elt, nil, n.Generators) + "]"
+	case *SetComp:
+		return "{" + unparseComprehension(n.Elt, nil, n.Generators) + "}"
+	case *GeneratorExp:
+		return "(" + unparseComprehension(n.Elt, nil, n.Generators) + ")"
+	case *DictComp:
+		return "{" + unparseComprehension(n.Key, n.Value, n.Generators) + "}"
+	}
+	return ""
+}
+
+func optionalExpr(expr Expr) string {
+	if expr == nil {
+		return ""
+	}
+	return unparseExpr(expr, precTest)
+}
+
+// unparseComprehension escribe el elemento de una comprensión (clave y
+// valor en un diccionario) seguido de sus cláusulas for e if.
+func unparseComprehension(element, value Expr, generators []*Comprehension) string {
+	text := unparseExpr(element, precTest)
+	if value != nil {
+		text += ": " + unparseExpr(value, precTest)
+	}
+	for _, generator := range generators {
+		if generator.Async {
+			text += " async"
+		}
+		text += " for " + unparseExpr(generator.Target, precTuple) + " in " + unparseExpr(generator.Iter, precOr)
+		for _, condition := range generator.Ifs {
+			text += " if " + unparseExpr(condition, precOr)
+		}
+	}
+	return text
+}
+
+func unparsePattern(pattern Pattern) string {
+	switch n := pattern.(type) {
+	case *MatchValue:
+		return unparseExpr(n.Value, precTest)
+	case *MatchSingleton:
+		return n.Value
+	case *MatchSequence:
+		return "[" + unparsePatterns(n.Patterns) + "]"
+	case *MatchStar:
+		if n.Name == "" {
+			return "*_"
+		}
+		return "*" + n.Name
+	case *MatchMapping:
+		parts := []string{}
+		for i, key := range n.Keys {
+			parts = append(parts, unparsePattern(key)+": "+unparsePattern(n.Patterns[i]))
+		}
+		if n.Rest != "" {
+			parts = append(parts, "**"+n.Rest)
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case *MatchClass:
+		parts := []string{}
+		for _, sub := range n.Patterns {
+			parts = append(parts, unparsePattern(sub))
+		}
+		for _, keyword := range n.Keywords {
+			parts = append(parts, keyword.Name+"="+unparsePattern(keyword.Pattern))
+		}
+		return unparseExpr(n.Cls, precAtom) + "(" + strings.Join(parts, ", ") + ")"
+	case *MatchAs:
+		switch {
+		case n.Pattern == nil && n.Name == "":
+			return "_"
+		case n.Pattern == nil:
+			return n.Name
+		}
+		// "a | b as c" agrupa todo el '|' antes del 'as'
+		if inner, ok := n.Pattern.(*MatchAs); ok && inner.Pattern != nil {
+			return "(" + unparsePattern(inner) + ") as " + n.Name
+		}
+		return unparsePattern(n.Pattern) + " as " + n.Name
+	case *MatchOr:
+		parts := []string{}
+		for _, alternative := range n.Patterns {
+			parts = append(parts, unparseAlternative(alternative))
+		}
+		return strings.Join(parts, " | ")
+	}
+	return ""
+}
+
+func unparsePatterns(patterns []Pattern) string {
+	parts := []string{}
+	for _, pattern := range patterns {
+		parts = append(parts, unparsePattern(pattern))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// unparseAlternative escribe una alternativa de '|', donde otro '|' o un
+// 'as' necesitan paréntesis.
+func unparseAlternative(pattern Pattern) string {
+	switch n := pattern.(type) {
+	case *MatchOr:
+		return "(" + unparsePattern(n) + ")"
+	case *MatchAs:
+		if n.Pattern != nil {
+			return "(" + unparsePattern(n) + ")"
+		}
+	}
+	return unparsePattern(pattern)
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+	
+	"examencorte2/src/lexer"
+)
+
+// TestUnparseRoundTrip verifica que Unparse escriba solo los paréntesis que
+// exige la precedencia: el código generado se vuelve a analizar y debe dar
+// el mismo árbol que el original.
+func TestUnparseRoundTrip(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"(1).real", "(1).real"},
+		{"a or (b or c)", "a or (b or c)"},
+		{"(a or b) or c", "(a or b) or c"},
+		{"(a < b) < c", "(a < b) < c"},
+		{"a < (b < c)", "a < (b < c)"},
+		{"a = (b := 1)", "a = (b := 1)"},
+		{"-(1 ** 2)", "-1 ** 2"},
+		{"(-1) ** 2", "(-1) ** 2"},
+		{"2 ** -1", "2 ** -1"},
+		{"a ** b ** c", "a ** b ** c"},
+		{"(a ** b) ** c", "(a ** b) ** c"},
+		{"a - (b - c)", "a - (b - c)"},
+		{"(a - b) - c", "a - b - c"},
+		{"a * (b + c)", "a * (b + c)"},
+		{"not (a and b)", "not (a and b)"},
+		{"(not a) == b", "(not a) == b"},
+		{"(a if b else c) if d else e", "(a if b else c) if d else e"},
+		{"a if b else (c if d else e)", "a if b else c if d else e"},
+		{"(lambda x: x)(1)", "(lambda x: x)(1)"},
+		{"f(*a, **b)", "f(*a, **b)"},
+		{"x = *a, b", "x = *a, b"},
+		{"(a, b) = c", "a, b = c"},
+		{"[*a, *b]", "[*a, *b]"},
+		{"{**a, 'b': 1}", "{**a, 'b': 1}"},
+		{"a[1:2, ::3]", "a[1:2, ::3]"},
+		{"a[(1, 2)]", "a[1, 2]"},
+		{"(a := 1, b)", "(a := 1), b"},
+		{"await x", "await x"},
+		{"-a.b", "-a.b"},
+		{"(-a).b", "(-a).b"},
+		{"(a + b).c", "(a + b).c"},
+		{"x = 1 if a else 2", "x = 1 if a else 2"},
+		{"a @ b @ c", "a @ b @ c"},
+		{"a | b ^ c & d", "a | b ^ c & d"},
+		{"(a | b) & c", "(a | b) & c"},
+		{"~(a + b)", "~(a + b)"},
+		{"f(x for x in y)", "f(x for x in y)"},
+		{"f((x for x in y), z)", "f((x for x in y), z)"},
+		{"x[a:=1]", "x[(a := 1)]"},
+		{"del (a, b), c", "del (a, b), c"},
+		{"for (a, b) in c:\n    pass", "for a, b in c:\n    pass"},
+		{"with (a, b) as c:\n    pass", "with (a, b) as c:\n    pass"},
+	}
+	
+	for _, test := range tests {
+		original := Analyze(lexer.Analyze(test.code + "\n").Tokens)
+		if !original.Success {
+			t.Errorf("%q: %v", test.code, original.Errors)
+			continue
+		}
+		got := Unparse(original.Module)
+		if got != test.want {
+			t.Errorf("Unparse(%q) = %q, se esperaba %q", test.code, got, test.want)
+		}
+		
+		reparsed := Analyze(lexer.Analyze(got + "\n").Tokens)
+		if !reparsed.Success {
+			t.Errorf("%q: el código generado %q no se puede analizar: %v", test.code, got, reparsed.Errors)
+			continue
+		}
+		if want, got := Dump(original.Module, DumpOptions{}), Dump(reparsed.Module, DumpOptions{}); got != want {
+			t.Errorf("%q: el árbol cambió al analizar %q:\n%s\n%s", test.code, Unparse(original.Module), want, got)
+		}
+	}
+}
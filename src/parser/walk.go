@@ -0,0 +1,266 @@
+package parser
+
+import "reflect"
+
+// Visitor recibe cada nodo en Walk. Si Visit devuelve un visitante w distinto
+// de nil, Walk visita los hijos del nodo con w y luego llama a w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk recorre el árbol en profundidad a partir de node, como ast.Walk de
+// Go. Un visitante nil en un nodo omite su subárbol.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range Children(node) {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect recorre el árbol llamando a f(node) en preorden. Si f devuelve
+// true se visitan los hijos del nodo y al terminar se llama a f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Traverse recorre el árbol llamando a pre antes de los hijos de cada nodo y
+// a post después. Si pre devuelve false no se visitan los hijos ni se llama
+// a post para ese nodo. Cualquiera de las dos funciones puede ser nil.
+func Traverse(node Node, pre func(Node) bool, post func(Node)) {
+	if pre != nil && !pre(node) {
+		return
+	}
+	for _, child := range Children(node) {
+		Traverse(child, pre, post)
+	}
+	if post != nil {
+		post(node)
+	}
+}
+
+// Children devuelve los hijos directos de un nodo en el orden en que
+// aparecen en el código. Los campos opcionales vacíos se omiten.
+func Children(node Node) []Node {
+	var children []Node
+	add := func(nodes ...Node) {
+		for _, child := range nodes {
+			if !isNil(child) {
+				children = append(children, child)
+			}
+		}
+	}
+	addExprs := func(list []Expr) {
+		for _, expr := range list {
+			add(expr)
+		}
+	}
+	addStmts := func(list []Stmt) {
+		for _, stmt := range list {
+			add(stmt)
+		}
+	}
+	addPatterns := func(list []Pattern) {
+		for _, pattern := range list {
+			add(pattern)
+		}
+	}
+	
+	switch n := node.(type) {
+	case *Module:
+		addStmts(n.Body)
+	case *Block:
+		addStmts(n.Body)
+	case *BadStmt:
+		for _, block := range n.Blocks {
+			add(block)
+		}
+		
+	case *FunctionDef:
+		for _, decorator := range n.Decorators {
+			add(decorator)
+		}
+		for _, param := range n.Params {
+			add(param)
+		}
+		add(n.Returns, n.Body)
+	case *Decorator:
+		add(n.Expr)
+	case *Parameter:
+		add(n.Annotation, n.Default)
+	case *ClassDef:
+		for _, decorator := range n.Decorators {
+			add(decorator)
+		}
+		addExprs(n.Bases)
+		for _, keyword := range n.Keywords {
+			add(keyword)
+		}
+		add(n.Body)
+	case *If:
+		add(n.Test, n.Body)
+	case *For:
+		add(n.Target, n.Iter, n.Body, n.Else)
+	case *Clause:
+		add(n.Body)
+	case *Try:
+		add(n.Body)
+		for _, handler := range n.Handlers {
+			add(handler)
+		}
+		add(n.Else, n.Finally)
+	case *ExceptHandler:
+		add(n.Type, n.Body)
+	case *Raise:
+		add(n.Exc, n.Cause)
+	case *With:
+		for _, item := range n.Items {
+			add(item)
+		}
+		add(n.Body)
+	case *WithItem:
+		add(n.Context, n.Target)
+	case *Match:
+		add(n.Subject)
+		for _, matchCase := range n.Cases {
+			add(matchCase)
+		}
+	case *MatchCase:
+		add(n.Pattern, n.Guard, n.Body)
+	case *Assign:
+		addExprs(n.Targets)
+		add(n.Value)
+	case *AugAssign:
+		add(n.Target, n.Value)
+	case *AnnAssign:
+		add(n.Target, n.Annotation, n.Value)
+	case *ExprStmt:
+		add(n.Value)
+	case *Global:
+		for _, name := range n.Names {
+			add(name)
+		}
+	case *Nonlocal:
+		for _, name := range n.Names {
+			add(name)
+		}
+	case *Delete:
+		addExprs(n.Targets)
+	case *Assert:
+		add(n.Test, n.Msg)
+		
+	case *List:
+		addExprs(n.Elts)
+	case *Tuple:
+		addExprs(n.Elts)
+	case *Set:
+		addExprs(n.Elts)
+	case *Dict:
+		for i, key := range n.Keys {
+			add(key, n.Values[i])
+		}
+	case *Starred:
+		add(n.Value)
+	case *Subscript:
+		add(n.Value, n.Index)
+	case *Slice:
+		add(n.Lower, n.Upper, n.Step)
+	case *Attribute:
+		add(n.Value)
+	case *Call:
+		add(n.Func)
+		addExprs(n.Args)
+		for _, keyword := range n.Keywords {
+			add(keyword)
+		}
+	case *Keyword:
+		add(n.Value)
+	case *UnaryOp:
+		add(n.Operand)
+	case *BinaryOp:
+		add(n.Left, n.Right)
+	case *Compare:
+		add(n.Left)
+		addExprs(n.Comparators)
+	case *BoolOp:
+		addExprs(n.Values)
+	case *IfExp:
+		add(n.Body, n.Test, n.Orelse)
+	case *Lambda:
+		for _, param := range n.Params {
+			add(param)
+		}
+		add(n.Body)
+	case *NamedExpr:
+		add(n.Target, n.Value)
+	case *ListComp:
+		add(n.Elt)
+		for _, generator := range n.Generators {
+			add(generator)
+		}
+	case *SetComp:
+		add(n.Elt)
+		for _, generator := range n.Generators {
+			add(generator)
+		}
+	case *GeneratorExp:
+		add(n.Elt)
+		for _, generator := range n.Generators {
+			add(generator)
+		}
+	case *DictComp:
+		add(n.Key, n.Value)
+		for _, generator := range n.Generators {
+			add(generator)
+		}
+	case *Comprehension:
+		add(n.Target, n.Iter)
+		addExprs(n.Ifs)
+	case *Await:
+		add(n.Value)
+		
+	case *MatchValue:
+		add(n.Value)
+	case *MatchSequence:
+		addPatterns(n.Patterns)
+	case *MatchMapping:
+		for i, key := range n.Keys {
+			add(key, n.Patterns[i])
+		}
+	case *MatchClass:
+		add(n.Cls)
+		addPatterns(n.Patterns)
+		for _, keyword := range n.Keywords {
+			add(keyword)
+		}
+	case *MatchKeyword:
+		add(n.Pattern)
+	case *MatchAs:
+		add(n.Pattern)
+	case *MatchOr:
+		addPatterns(n.Patterns)
+	}
+	
+	return children
+}
+
+// isNil indica si node es nil o un puntero nil guardado en la interfaz,
+// como el Body *Block de una sentencia incompleta.
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	value := reflect.ValueOf(node)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
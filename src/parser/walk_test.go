@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	
+	"examencorte2/src/lexer"
+)
+
+func parseModule(t *testing.T, code string) *Module {
+	t.Helper()
+	result := Analyze(lexer.Analyze(code).Tokens)
+	if !result.Success {
+		t.Fatalf("%q: %v", code, result.Errors)
+	}
+	return result.Module
+}
+
+func nodeType(node Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "*parser.")
+}
+
+func TestInspectSkipsSubtree(t *testing.T) {
+	module := parseModule(t, "def f(a):\n    b = a\nc = 1\n")
+	var names []string
+	Inspect(module, func(node Node) bool {
+		if id, ok := node.(*Identifier); ok {
+			names = append(names, id.Name)
+		}
+		_, function := node.(*FunctionDef)
+		return !function
+	})
+	if want := []string{"c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("nombres = %v, se esperaba %v", names, want)
+	}
+}
+
+func TestTraversePostOrder(t *testing.T) {
+	module := parseModule(t, "x = a + b\n")
+	var post []string
+	Traverse(module, nil, func(node Node) {
+		post = append(post, nodeType(node))
+	})
+	want := []string{"Identifier", "Identifier", "Identifier", "BinaryOp", "Assign", "Module"}
+	if !reflect.DeepEqual(post, want) {
+		t.Errorf("post = %v, se esperaba %v", post, want)
+	}
+	
+	// Sin pre ni post para el subárbol omitido
+	post = nil
+	Traverse(module, func(node Node) bool {
+		_, binary := node.(*BinaryOp)
+		return !binary
+	}, func(node Node) {
+		post = append(post, nodeType(node))
+	})
+	if want := []string{"Identifier", "Assign", "Module"}; !reflect.DeepEqual(post, want) {
+		t.Errorf("post = %v, se esperaba %v", post, want)
+	}
+}
+
+// closings cuenta las llamadas a Visit(nil), una por cada nodo cuyos hijos
+// se visitaron.
+type closings struct{ opened, closed *int }
+
+func (c closings) Visit(node Node) Visitor {
+	if node == nil {
+		*c.closed++
+	} else {
+		*c.opened++
+	}
+	return c
+}
+
+func TestWalkClosesEveryNode(t *testing.T) {
+	module := parseModule(t, "if a:\n    b = [c, d]\n")
+	opened, closed := 0, 0
+	Walk(closings{&opened, &closed}, module)
+	if opened == 0 || opened != closed {
+		t.Errorf("Visit(nodo) = %d, Visit(nil) = %d", opened, closed)
+	}
+}
+
+func TestTransformRewrites(t *testing.T) {
+	module := parseModule(t, "x = a + a\npass\nif a:\n    pass\n    y = a\n")
+	Transform(module, func(node Node) Node {
+		switch n := node.(type) {
+		case *Identifier:
+			if n.Name == "a" {
+				return &Number{Position: n.Position, Value: "1"}
+			}
+		case *Pass:
+			return nil
+		case *Number:
+			// Una expresión no puede ocupar el lugar de una sentencia
+			return &Pass{}
+		}
+		return node
+	})
+	if got, want := Unparse(module), "x = 1 + 1\nif 1:\n    y = 1"; got != want {
+		t.Errorf("Unparse = %q, se esperaba %q", got, want)
+	}
+}
+
+// Las sentencias armadas a mano pueden dejar sin completar campos que son
+// punteros, como el cuerpo o el else.
+func TestNilFieldsAreSkipped(t *testing.T) {
+	nodes := []Node{
+		&FunctionDef{Name: "f", Returns: (*Identifier)(nil)},
+		&ClassDef{Name: "C"},
+		&If{Test: &Identifier{Name: "a"}},
+		&For{Target: &Identifier{Name: "x"}, Iter: &Identifier{Name: "y"}},
+		&Try{Else: &Clause{}},
+		&ExceptHandler{},
+		&With{},
+		&MatchCase{Pattern: &MatchAs{}},
+		&Module{Body: []Stmt{&If{}, (*Pass)(nil)}},
+	}
+	for _, node := range nodes {
+		Inspect(node, func(child Node) bool {
+			if child != nil && isNil(child) {
+				t.Errorf("%s: Inspect visitó un %s nil", nodeType(node), nodeType(child))
+			}
+			return true
+		})
+		Traverse(node, nil, func(Node) {})
+		Transform(node, func(child Node) Node { return child })
+	}
+}
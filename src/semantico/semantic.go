@@ -14,279 +14,2098 @@ const (
 	StringType
 	BoolType
 	UnknownType
+	ListType
+	TupleType
+	DictType
+	SetType
+	FloatType
+	NoneType
 )
 
 type Variable struct {
 	Name string
 	Type VarType
 	Line int
+	// Para colecciones: tipo de los elementos (de los valores en un dict)
+	// y de las claves, cuando todos coinciden.
+	ElementType VarType
+	KeyType     VarType
 }
 
 type SemanticResult struct {
 	Errors           []string              `json:"errors"`
+	Warnings         []string              `json:"warnings"`
 	Variables        map[string]Variable   `json:"variables"`
 	TypeMismatches   []string              `json:"type_mismatches"`
 	Success          bool                  `json:"success"`
 }
 
+// scope guarda los nombres ligados en un módulo, una clase, una función o
+// una lambda, junto con los tipos que declaran sus anotaciones.
+type scope struct {
+	kind        string
+	async       bool
+	variables   map[string]Variable
+	annotations map[string]declaredType
+	
+	// Nombres declarados global o nonlocal, nombres usados hasta ahora y,
+	// en una función, todos los nombres que liga su cuerpo
+	declarations map[string]string
+	used         map[string]bool
+	bound        map[string]bool
+}
+
+// declaredType es el tipo indicado por una anotación como "list[int]" u
+// "Optional[str]".
+type declaredType struct {
+	Type        VarType
+	ElementType VarType
+	KeyType     VarType
+	Optional    bool
+}
+
 type SemanticAnalyzer struct {
+	// Variables del módulo; las de cada función viven en su propio ámbito
 	variables map[string]Variable
+	scopes    []*scope
 	errors    []string
+	warnings  []string
 	tokens    []lexer.Token
+	
+	// Funciones definidas, para verificar los argumentos de las llamadas
+	functions map[string]*parser.FunctionDef
+	
+	// Manejo de excepciones
+	handlerDepth   int
+	handlerNames   map[string]int
+}
+
+// Atributos y métodos de los tipos predefinidos con el tipo que devuelven
+// (UnknownType cuando no se puede deducir o devuelven None).
+var typeAttributes = map[VarType]map[string]VarType{
+	IntType: {
+		"bit_length": IntType, "bit_count": IntType, "to_bytes": UnknownType,
+		"from_bytes": IntType, "conjugate": IntType, "as_integer_ratio": TupleType,
+		"is_integer": BoolType, "real": IntType, "imag": IntType,
+		"numerator": IntType, "denominator": IntType,
+	},
+	FloatType: {
+		"is_integer": BoolType, "as_integer_ratio": TupleType, "hex": StringType,
+		"fromhex": FloatType, "conjugate": FloatType, "real": FloatType,
+		"imag": FloatType,
+	},
+	StringType: {
+		"lower": StringType, "upper": StringType, "strip": StringType,
+		"lstrip": StringType, "rstrip": StringType, "title": StringType,
+		"capitalize": StringType, "casefold": StringType, "swapcase": StringType,
+		"replace": StringType, "join": StringType, "format": StringType,
+		"format_map": StringType, "center": StringType, "ljust": StringType,
+		"rjust": StringType, "zfill": StringType, "expandtabs": StringType,
+		"removeprefix": StringType, "removesuffix": StringType,
+		"translate": StringType, "maketrans": DictType, "encode": UnknownType,
+		"split": ListType, "rsplit": ListType, "splitlines": ListType,
+		"partition": TupleType, "rpartition": TupleType,
+		"find": IntType, "rfind": IntType, "index": IntType, "rindex": IntType,
+		"count": IntType, "startswith": BoolType, "endswith": BoolType,
+		"isdigit": BoolType, "isalpha": BoolType, "isalnum": BoolType,
+		"isspace": BoolType, "isupper": BoolType, "islower": BoolType,
+		"istitle": BoolType, "isnumeric": BoolType, "isdecimal": BoolType,
+		"isidentifier": BoolType, "isprintable": BoolType, "isascii": BoolType,
+	},
+	ListType: {
+		"append": UnknownType, "extend": UnknownType, "insert": UnknownType,
+		"remove": UnknownType, "pop": UnknownType, "clear": UnknownType,
+		"sort": UnknownType, "reverse": UnknownType, "copy": ListType,
+		"index": IntType, "count": IntType,
+	},
+	TupleType: {
+		"index": IntType, "count": IntType,
+	},
+	DictType: {
+		"keys": UnknownType, "values": UnknownType, "items": UnknownType,
+		"get": UnknownType, "pop": UnknownType, "popitem": TupleType,
+		"setdefault": UnknownType, "update": UnknownType, "clear": UnknownType,
+		"copy": DictType, "fromkeys": DictType,
+	},
+	SetType: {
+		"add": UnknownType, "remove": UnknownType, "discard": UnknownType,
+		"pop": UnknownType, "clear": UnknownType, "copy": SetType,
+		"union": SetType, "intersection": SetType, "difference": SetType,
+		"symmetric_difference": SetType, "update": UnknownType,
+		"intersection_update": UnknownType, "difference_update": UnknownType,
+		"symmetric_difference_update": UnknownType, "issubset": BoolType,
+		"issuperset": BoolType, "isdisjoint": BoolType,
+	},
+}
+
+// Jerarquía de las excepciones predefinidas: cada clase apunta a su base.
+var builtinExceptions = map[string]string{
+	"BaseException":       "",
+	"SystemExit":          "BaseException",
+	"KeyboardInterrupt":   "BaseException",
+	"GeneratorExit":       "BaseException",
+	"Exception":           "BaseException",
+	"ArithmeticError":     "Exception",
+	"ZeroDivisionError":   "ArithmeticError",
+	"OverflowError":       "ArithmeticError",
+	"FloatingPointError":  "ArithmeticError",
+	"LookupError":         "Exception",
+	"IndexError":          "LookupError",
+	"KeyError":            "LookupError",
+	"ValueError":          "Exception",
+	"UnicodeError":        "ValueError",
+	"UnicodeDecodeError":  "UnicodeError",
+	"UnicodeEncodeError":  "UnicodeError",
+	"TypeError":           "Exception",
+	"NameError":           "Exception",
+	"UnboundLocalError":   "NameError",
+	"AttributeError":      "Exception",
+	"ImportError":         "Exception",
+	"ModuleNotFoundError": "ImportError",
+	"OSError":             "Exception",
+	"FileNotFoundError":   "OSError",
+	"FileExistsError":     "OSError",
+	"PermissionError":     "OSError",
+	"IsADirectoryError":   "OSError",
+	"TimeoutError":        "OSError",
+	"RuntimeError":        "Exception",
+	"NotImplementedError": "RuntimeError",
+	"RecursionError":      "RuntimeError",
+	"StopIteration":       "Exception",
+	"AssertionError":      "Exception",
+	"EOFError":            "Exception",
 }
 
-func Analyze(tokens []lexer.Token, ast *parser.ASTNode) SemanticResult {
+func Analyze(tokens []lexer.Token, module *parser.Module) SemanticResult {
 	analyzer := &SemanticAnalyzer{
-		variables: make(map[string]Variable),
-		errors:    []string{},
-		tokens:    tokens,
+		variables:    make(map[string]Variable),
+		errors:       []string{},
+		warnings:     []string{},
+		tokens:       tokens,
+		functions:    make(map[string]*parser.FunctionDef),
+		handlerNames: make(map[string]int),
 	}
 	
-	if ast != nil {
-		analyzer.analyzeNode(ast)
+	analyzer.scopes = []*scope{newScope("module", analyzer.variables)}
+	
+	if module != nil {
+		analyzer.analyzeNode(module)
 	}
 	
 	return SemanticResult{
 		Errors:         analyzer.errors,
+		Warnings:       analyzer.warnings,
 		Variables:      analyzer.variables,
 		TypeMismatches: analyzer.getTypeMismatches(),
 		Success:        len(analyzer.errors) == 0,
 	}
 }
 
-func (sa *SemanticAnalyzer) analyzeNode(node *parser.ASTNode) {
-	if node == nil {
-		return
-	}
-	
-	switch node.Type {
-	case "Program":
-		for _, child := range node.Children {
-			sa.analyzeNode(child)
+func (sa *SemanticAnalyzer) analyzeNode(node parser.Node) {
+	switch n := node.(type) {
+	case *parser.Module:
+		for _, stmt := range n.Body {
+			sa.analyzeNode(stmt)
+		}
+		
+	case *parser.Block:
+		for _, stmt := range n.Body {
+			sa.analyzeNode(stmt)
 		}
 		
-	case "FunctionDef":
-		// Analizar parámetros y cuerpo de función
-		for _, child := range node.Children {
-			sa.analyzeNode(child)
+	case *parser.BadStmt:
+		// Los bloques que seguían a la sentencia con error
+		for _, block := range n.Blocks {
+			sa.analyzeNode(block)
 		}
 		
-	case "Assignment":
-		sa.analyzeAssignment(node)
+	case *parser.FunctionDef:
+		sa.analyzeFunctionDef(n)
 		
-	case "IfStatement":
-		sa.analyzeIfStatement(node)
+	case *parser.ClassDef:
+		sa.analyzeClassDef(n)
 		
-	case "Block":
-		for _, child := range node.Children {
-			sa.analyzeNode(child)
-		}
+	case *parser.For:
+		sa.analyzeFor(n)
+		
+	case *parser.Await:
+		sa.checkAsyncContext(n.Line, "'await'")
+		sa.analyzeNode(n.Value)
+		
+	case *parser.Assign:
+		sa.analyzeAssignment(n)
+		
+	case *parser.AnnAssign:
+		sa.analyzeAnnAssign(n)
+		
+	case *parser.AugAssign:
+		sa.analyzeAugAssign(n)
+		
+	case *parser.If:
+		sa.analyzeIfStatement(n)
+		
+	case *parser.ExprStmt:
+		sa.analyzeNode(n.Value)
+		
+	case *parser.BinaryOp:
+		sa.analyzeBinaryOperation(n)
+		
+	case *parser.Compare:
+		sa.analyzeComparisonChain(n)
+		
+	case *parser.Lambda:
+		sa.analyzeLambda(n)
+		
+	case *parser.NamedExpr:
+		sa.analyzeNamedExpr(n)
+		
+	case *parser.ListComp, *parser.SetComp, *parser.DictComp, *parser.GeneratorExp:
+		sa.analyzeComprehension(n)
+		
+	case *parser.Call:
+		sa.analyzeFunctionCall(n)
+		
+	case *parser.Attribute:
+		sa.analyzeAttribute(n)
+		
+	case *parser.Try:
+		sa.analyzeTry(n)
 		
-	case "ExpressionStatement":
-		for _, child := range node.Children {
-			sa.analyzeNode(child)
+	case *parser.Raise:
+		sa.analyzeRaise(n)
+		
+	case *parser.With:
+		sa.analyzeWith(n)
+		
+	case *parser.Match:
+		sa.analyzeMatch(n)
+		
+	case *parser.Identifier:
+		sa.currentScope().used[n.Name] = true
+		sa.checkHandlerName(n)
+		
+	case *parser.Global:
+		sa.analyzeNameDeclaration(n.Line, "global", n.Names)
+		
+	case *parser.Nonlocal:
+		sa.analyzeNameDeclaration(n.Line, "nonlocal", n.Names)
+		
+	case *parser.Delete:
+		for _, target := range n.Targets {
+			sa.deleteTarget(target)
 		}
 		
-	case "BinaryOp":
-		sa.analyzeBinaryOperation(node)
+	case *parser.Assert:
+		sa.analyzeAssert(n)
+		
+	case *parser.Dict, *parser.Set:
+		sa.analyzeHashable(n)
+		
+	case *parser.Subscript:
+		sa.analyzeSubscript(n)
 		
-	case "FunctionCall", "MethodCall":
-		sa.analyzeFunctionCall(node)
+	case *parser.List:
+		sa.analyzeExprs(n.Elts)
 		
+	case *parser.Tuple:
+		sa.analyzeExprs(n.Elts)
+		
+	case *parser.BoolOp:
+		sa.analyzeExprs(n.Values)
+		
+	case *parser.UnaryOp:
+		sa.analyzeNode(n.Operand)
+		
+	case *parser.IfExp:
+		sa.analyzeExprs([]parser.Expr{n.Test, n.Body, n.Orelse})
+		
+	case *parser.Starred:
+		sa.analyzeNode(n.Value)
+		
+	case *parser.Slice:
+		// Las partes omitidas son nil y no se analizan
+		sa.analyzeExprs([]parser.Expr{n.Lower, n.Upper, n.Step})
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeExprs(exprs []parser.Expr) {
+	for _, expr := range exprs {
+		sa.analyzeNode(expr)
+	}
+}
+
+// analyzeClause analiza el bloque de un else o finally, si existe.
+func (sa *SemanticAnalyzer) analyzeClause(clause *parser.Clause) {
+	if clause != nil {
+		sa.analyzeNode(clause.Body)
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeFunctionDef(node *parser.FunctionDef) {
+	// Los métodos no se llaman por su nombre, así que no se registran
+	if sa.currentScope().kind != "class" {
+		sa.functions[node.Name] = node
+	}
+	
+	// Los decoradores y valores por defecto se evalúan fuera de la función
+	for _, decorator := range node.Decorators {
+		sa.analyzeNode(decorator.Expr)
+	}
+	defaults := map[string]Variable{}
+	for _, param := range node.Params {
+		if param.Default != nil {
+			sa.analyzeNode(param.Default)
+			defaults[param.Name] = sa.valueVariable(param.Name, param.Default, param.Line)
+		}
+	}
+	sa.declare(newVariable(node.Name, UnknownType, node.Line))
+	
+	sa.pushScope("function")
+	sa.currentScope().async = node.Async
+	for _, param := range node.Params {
+		sa.currentScope().bound[param.Name] = true
+	}
+	boundNames(node.Body, sa.currentScope().bound)
+	for _, param := range node.Params {
+		sa.declareParameter(param, defaults)
+	}
+	sa.analyzeNode(node.Body)
+	sa.popScope()
+}
+
+// declareParameter liga un parámetro dentro de la función. Con anotación,
+// el valor por defecto debe ser compatible; en *args y **kwargs la anotación
+// indica el tipo de cada elemento.
+func (sa *SemanticAnalyzer) declareParameter(param *parser.Parameter, defaults map[string]Variable) {
+	variable := newVariable(param.Name, parameterType(param), param.Line)
+	
+	declared, annotated := sa.annotationType(param.Annotation)
+	switch {
+	case !annotated:
+	case param.Kind == parser.VarArgsParam:
+		variable.ElementType = declared.Type
+	case param.Kind == parser.KwArgsParam:
+		variable.KeyType = StringType
+		variable.ElementType = declared.Type
 	default:
-		// Analizar hijos por defecto
-		for _, child := range node.Children {
-			sa.analyzeNode(child)
+		sa.currentScope().annotations[param.Name] = declared
+		if value, exists := defaults[param.Name]; exists {
+			sa.checkElements(value, param.Default)
+			variable = value
+		} else {
+			variable = declared.variable(param.Name, param.Line)
 		}
 	}
+	
+	sa.declare(variable)
 }
 
-func (sa *SemanticAnalyzer) analyzeAssignment(node *parser.ASTNode) {
-	varName := node.Value
+// analyzeAnnAssign analiza "destino: tipo = valor". La anotación de un
+// nombre se registra en el ámbito actual y se verifica en cada asignación
+// posterior.
+func (sa *SemanticAnalyzer) analyzeAnnAssign(node *parser.AnnAssign) {
+	sa.analyzeNode(node.Value)
 	
-	if len(node.Children) == 0 {
-		sa.addError(node.Line, "Asignación sin valor")
+	target, isName := node.Target.(*parser.Identifier)
+	if !isName {
+		sa.analyzeNode(node.Target)
 		return
 	}
 	
-	valueNode := node.Children[0]
-	varType := sa.inferType(valueNode)
+	if keyword, exists := sa.currentScope().declarations[target.Name]; exists {
+		sa.addError(node.Line,
+			fmt.Sprintf("No se puede anotar el tipo de '%s' porque se declaró %s", target.Name, keyword))
+	} else if declared, annotated := sa.annotationType(node.Annotation); annotated {
+		sa.currentScope().annotations[target.Name] = declared
+	}
+	if node.Value != nil {
+		sa.assignTarget(target, node.Value, node.Line)
+	}
+}
+
+// annotationType interpreta una anotación de tipo. Devuelve false si no hay
+// anotación o si nombra un tipo que el analizador no conoce.
+func (sa *SemanticAnalyzer) annotationType(node parser.Expr) (declaredType, bool) {
+	declared := declaredType{Type: UnknownType, ElementType: UnknownType, KeyType: UnknownType}
 	
-	// Registrar o actualizar variable
-	sa.variables[varName] = Variable{
-		Name: varName,
-		Type: varType,
-		Line: node.Line,
+	switch n := node.(type) {
+	case *parser.None:
+		declared.Type = NoneType
+		return declared, true
+		
+	case *parser.Identifier, *parser.Attribute:
+		// typing.List y List equivalen a list
+		varType, known := annotationNames[strings.ToLower(typeName(n))]
+		declared.Type = varType
+		return declared, known
+		
+	case *parser.BinaryOp:
+		// int | None
+		if n.Op != "|" {
+			return declared, false
+		}
+		left, leftKnown := sa.annotationType(n.Left)
+		right, rightKnown := sa.annotationType(n.Right)
+		switch {
+		case leftKnown && right.Type == NoneType:
+			left.Optional = true
+			return left, true
+		case rightKnown && left.Type == NoneType:
+			right.Optional = true
+			return right, true
+		}
+		return declared, false
+		
+	case *parser.Subscript:
+		base := typeName(n.Value)
+		if base == "" {
+			return declared, false
+		}
+		arguments := []parser.Expr{n.Index}
+		if tuple, isTuple := n.Index.(*parser.Tuple); isTuple {
+			arguments = tuple.Elts
+		}
+		
+		if base == "Optional" {
+			inner, known := sa.annotationType(arguments[0])
+			inner.Optional = true
+			return inner, known || len(arguments) == 1
+		}
+		
+		varType, known := annotationNames[strings.ToLower(base)]
+		if !known {
+			return declared, false
+		}
+		declared.Type = varType
+		switch {
+		case varType == DictType && len(arguments) == 2:
+			declared.KeyType = sa.annotationElement(arguments[0])
+			declared.ElementType = sa.annotationElement(arguments[1])
+		case varType == ListType || varType == SetType:
+			declared.ElementType = sa.annotationElement(arguments[0])
+		case varType == TupleType && len(arguments) == 2:
+			if _, isEllipsis := arguments[1].(*parser.Ellipsis); isEllipsis {
+				declared.ElementType = sa.annotationElement(arguments[0])
+			}
+		}
+		return declared, true
 	}
 	
-	sa.analyzeNode(valueNode)
+	return declared, false
 }
 
-func (sa *SemanticAnalyzer) analyzeIfStatement(node *parser.ASTNode) {
-	if len(node.Children) < 1 {
-		sa.addError(node.Line, "Declaración if sin condición")
-		return
+// typeName devuelve el nombre de un tipo escrito como "List" o
+// "typing.List", o "" si la expresión no es un nombre.
+func typeName(node parser.Expr) string {
+	switch n := node.(type) {
+	case *parser.Identifier:
+		return n.Name
+	case *parser.Attribute:
+		return n.Attr
+	}
+	return ""
+}
+
+// annotationElement devuelve el tipo de los elementos de una colección
+// anotada. Los elementos opcionales no se verifican.
+func (sa *SemanticAnalyzer) annotationElement(node parser.Expr) VarType {
+	declared, known := sa.annotationType(node)
+	if !known || declared.Optional {
+		return UnknownType
+	}
+	return declared.Type
+}
+
+var annotationNames = map[string]VarType{
+	"int": IntType, "str": StringType, "bool": BoolType, "float": FloatType,
+	"list": ListType, "tuple": TupleType, "dict": DictType, "set": SetType,
+	"none": NoneType,
+}
+
+// accepts indica si un valor es compatible con el tipo declarado. Como en
+// Python, un int es válido donde se espera float y un bool donde se espera
+// int.
+func (d declaredType) accepts(value Variable) bool {
+	if !acceptsType(d.Type, value.Type) {
+		return value.Type == NoneType && d.Optional
+	}
+	return acceptsType(d.ElementType, value.ElementType) && acceptsType(d.KeyType, value.KeyType)
+}
+
+func acceptsType(declared, actual VarType) bool {
+	switch {
+	case declared == UnknownType || actual == UnknownType || declared == actual:
+		return true
+	case declared == FloatType:
+		return actual == IntType || actual == BoolType
+	case declared == IntType:
+		return actual == BoolType
+	}
+	return false
+}
+
+// variable devuelve una variable con el tipo declarado.
+func (d declaredType) variable(name string, line int) Variable {
+	variable := newVariable(name, d.Type, line)
+	variable.ElementType = d.ElementType
+	variable.KeyType = d.KeyType
+	return variable
+}
+
+func (d declaredType) String() string {
+	name := describeType(d.variable("", 0))
+	if d.Optional {
+		return fmt.Sprintf("Optional[%s]", name)
+	}
+	return name
+}
+
+// describeType escribe el tipo de una variable con el de sus elementos,
+// por ejemplo "list[int]" o "dict[string, int]".
+func describeType(variable Variable) string {
+	switch {
+	case variable.Type == DictType && (variable.KeyType != UnknownType || variable.ElementType != UnknownType):
+		return fmt.Sprintf("dict[%s, %s]", variable.KeyType, variable.ElementType)
+	case variable.Type == ListType || variable.Type == SetType || variable.Type == TupleType:
+		if variable.ElementType != UnknownType {
+			return fmt.Sprintf("%s[%s]", variable.Type, variable.ElementType)
+		}
+	}
+	return variable.Type.String()
+}
+
+// analyzeClassDef analiza los decoradores y las bases en el ámbito que
+// contiene la clase y el cuerpo en un ámbito propio.
+func (sa *SemanticAnalyzer) analyzeClassDef(node *parser.ClassDef) {
+	for _, decorator := range node.Decorators {
+		sa.analyzeNode(decorator.Expr)
+	}
+	sa.analyzeExprs(node.Bases)
+	for _, keyword := range node.Keywords {
+		sa.analyzeNode(keyword.Value)
 	}
 	
-	condition := node.Children[0]
-	sa.analyzeCondition(condition)
+	sa.pushScope("class")
+	sa.analyzeNode(node.Body)
+	sa.popScope()
 	
-	// Analizar el resto de los hijos (bloque then, etc.)
-	for _, child := range node.Children {
-		sa.analyzeNode(child)
+	sa.declare(newVariable(node.Name, UnknownType, node.Line))
+}
+
+// analyzeFor liga el destino con los elementos del iterable y analiza el
+// bloque y el else.
+func (sa *SemanticAnalyzer) analyzeFor(node *parser.For) {
+	if node.Async {
+		sa.checkAsyncContext(node.Line, "'async for'")
+	}
+	
+	sa.analyzeNode(node.Iter)
+	sa.bindIterationTarget(node.Target, node.Iter, node.Line)
+	
+	sa.analyzeNode(node.Body)
+	sa.analyzeClause(node.Else)
+}
+
+// checkAsyncContext informa un error si la construcción no está dentro del
+// cuerpo de una función async.
+func (sa *SemanticAnalyzer) checkAsyncContext(line int, construct string) {
+	if !sa.inAsyncFunction() {
+		sa.addError(line, fmt.Sprintf("%s fuera de una función async", construct))
+	}
+}
+
+// inAsyncFunction indica si el código actual pertenece a una función async.
+// Las comprensiones heredan el contexto de la función que las contiene.
+func (sa *SemanticAnalyzer) inAsyncFunction() bool {
+	for i := len(sa.scopes) - 1; i >= 0; i-- {
+		if sa.scopes[i].kind != "comprehension" {
+			return sa.scopes[i].async
+		}
+	}
+	return false
+}
+
+// sequenceElements devuelve los elementos de una tupla o lista literal.
+func sequenceElements(node parser.Expr) ([]parser.Expr, bool) {
+	switch n := node.(type) {
+	case *parser.Tuple:
+		return n.Elts, true
+	case *parser.List:
+		return n.Elts, true
+	}
+	return nil, false
+}
+
+// analyzeAssignment analiza "destino = ... = valor". Cada destino recibe el
+// tipo del valor; las tuplas y listas se desempaquetan elemento a elemento.
+func (sa *SemanticAnalyzer) analyzeAssignment(node *parser.Assign) {
+	sa.analyzeNode(node.Value)
+	
+	for _, target := range node.Targets {
+		sa.assignTarget(target, node.Value, node.Line)
+	}
+}
+
+// assignTarget liga un destino de asignación con el nodo de su valor.
+func (sa *SemanticAnalyzer) assignTarget(target, valueNode parser.Expr, line int) {
+	switch t := target.(type) {
+	case *parser.Identifier:
+		// Registrar o actualizar variable
+		variable := sa.valueVariable(t.Name, valueNode, line)
+		sa.checkElements(variable, valueNode)
+		sa.declare(variable)
+		
+	case *parser.Tuple, *parser.List:
+		sa.unpackTargets(target, valueNode, line)
+		
+	case *parser.Subscript:
+		switch targetType := sa.inferType(t.Value); targetType {
+		case StringType, TupleType:
+			sa.addError(line,
+				fmt.Sprintf("El tipo '%s' no admite asignación por índice", targetType))
+		}
+		sa.analyzeNode(target)
+		
+	default:
+		sa.analyzeNode(target)
 	}
 }
 
-func (sa *SemanticAnalyzer) analyzeCondition(node *parser.ASTNode) {
-	if node == nil {
+// unpackTargets analiza "a, *b = valor". Si el valor es una tupla o lista
+// literal se compara la cantidad de elementos y cada destino recibe el tipo
+// del elemento correspondiente.
+func (sa *SemanticAnalyzer) unpackTargets(target, valueNode parser.Expr, line int) {
+	targets, _ := sequenceElements(target)
+	starred := -1
+	for i, element := range targets {
+		if _, isStarred := element.(*parser.Starred); isStarred {
+			starred = i
+		}
+	}
+	
+	if values, isSequence := sequenceElements(valueNode); isSequence {
+		hasStarredValue := false
+		for _, value := range values {
+			_, isStarred := value.(*parser.Starred)
+			hasStarredValue = hasStarredValue || isStarred
+		}
+		
+		if !hasStarredValue {
+			count := len(targets)
+			if starred < 0 && len(values) != count {
+				sa.addError(line,
+					fmt.Sprintf("No se pueden desempaquetar %d valores en %d destinos", len(values), count))
+				return
+			}
+			if starred >= 0 && len(values) < count-1 {
+				sa.addError(line,
+					fmt.Sprintf("Se necesitan al menos %d valores para desempaquetar pero hay %d", count-1, len(values)))
+				return
+			}
+			
+			// Los valores se evalúan antes de ligar los nombres: a, b = b, a
+			pending := []Variable{}
+			for i, element := range targets {
+				if i == starred {
+					sa.bindTarget(element.(*parser.Starred).Value, ListType, line)
+					continue
+				}
+				value := values[i]
+				if starred >= 0 && i > starred {
+					value = values[len(values)-(count-i)]
+				}
+				name, isName := element.(*parser.Identifier)
+				if !isName {
+					sa.assignTarget(element, value, line)
+					continue
+				}
+				variable := newVariable(name.Name, sa.inferType(value), line)
+				variable.ElementType, variable.KeyType = sa.inferElementTypes(value)
+				pending = append(pending, variable)
+			}
+			for _, variable := range pending {
+				sa.declare(variable)
+			}
+			return
+		}
+	}
+	
+	switch valueType := sa.inferType(valueNode); valueType {
+	case IntType, BoolType:
+		sa.addError(line,
+			fmt.Sprintf("No se puede desempaquetar un valor de tipo '%s'", valueType))
 		return
 	}
 	
-	if node.Type == "BinaryOp" {
-		sa.analyzeBinaryOperation(node)
-	} else {
-		sa.analyzeNode(node)
+	element, _ := sa.inferElementTypes(valueNode)
+	if sa.inferType(valueNode) == StringType {
+		element = StringType
+	}
+	for i, child := range targets {
+		if i == starred {
+			sa.bindTarget(child.(*parser.Starred).Value, ListType, line)
+		} else {
+			sa.bindTarget(child, element, line)
+		}
 	}
 }
 
-func (sa *SemanticAnalyzer) analyzeBinaryOperation(node *parser.ASTNode) {
-	if len(node.Children) < 2 {
-		sa.addError(node.Line, "Operación binaria incompleta")
+// analyzeAugAssign verifica que el operador aumentado sea válido para el
+// tipo actual del destino y actualiza el tipo de la variable.
+func (sa *SemanticAnalyzer) analyzeAugAssign(node *parser.AugAssign) {
+	value := node.Value
+	operator := node.Op
+	
+	sa.analyzeNode(value)
+	
+	target, isName := node.Target.(*parser.Identifier)
+	if !isName {
+		sa.analyzeNode(node.Target)
+		return
+	}
+	
+	variable, exists := sa.lookup(target.Name)
+	if !exists {
+		sa.addError(node.Line,
+			fmt.Sprintf("La variable '%s' se usa en '%s' antes de ser asignada", target.Name, operator))
+		return
+	}
+	
+	valueType := sa.inferType(value)
+	resultType, valid := augmentedResultType(operator, variable.Type, valueType)
+	if !valid {
+		sa.addError(node.Line,
+			fmt.Sprintf("No se puede aplicar '%s' a '%s' (%s) con un valor de tipo %s", operator, target.Name, variable.Type, valueType))
 		return
 	}
 	
-	leftNode := node.Children[0]
-	rightNode := node.Children[1]
-	operator := node.Value
+	if resultType != variable.Type {
+		updated := newVariable(target.Name, resultType, node.Line)
+		sa.declare(updated)
+	}
+}
+
+// augmentedResultType devuelve el tipo que resulta de aplicar el operador
+// aumentado y si la combinación de tipos es válida. Los tipos desconocidos
+// se aceptan siempre.
+func augmentedResultType(operator string, current, value VarType) (VarType, bool) {
+	if current == UnknownType || value == UnknownType {
+		return current, true
+	}
+	numeric := func(t VarType) bool { return t == IntType || t == BoolType }
+	
+	switch current {
+	case IntType, BoolType, FloatType:
+		// El resultado es float si algún operando lo es, o al dividir con '/'
+		resultType := IntType
+		if operator == "/=" || current == FloatType || value == FloatType {
+			resultType = FloatType
+		}
+		switch operator {
+		case "+=", "-=", "/=", "//=", "%=", "**=":
+			return resultType, numeric(value) || value == FloatType
+		case ">>=", "<<=", "&=", "|=", "^=":
+			return IntType, numeric(current) && numeric(value)
+		case "*=":
+			if numeric(value) || value == FloatType {
+				return resultType, true
+			}
+			// 3 * "ab" y 3 * [1] repiten la secuencia
+			return value, numeric(current) && (value == StringType || value == ListType || value == TupleType)
+		}
+		
+	case StringType, TupleType:
+		switch operator {
+		case "+=":
+			return current, value == current
+		case "*=":
+			return current, numeric(value)
+		}
+		
+	case ListType:
+		switch operator {
+		case "+=":
+			// list += acepta cualquier iterable
+			return current, !numeric(value)
+		case "*=":
+			return current, numeric(value)
+		}
+		
+	case SetType:
+		switch operator {
+		case "|=", "&=", "-=", "^=":
+			return current, value == SetType
+		}
+		
+	case DictType:
+		return current, operator == "|=" && value == DictType
+	}
+	
+	return current, false
+}
+
+func (sa *SemanticAnalyzer) analyzeIfStatement(node *parser.If) {
+	sa.analyzeCondition(node.Test)
+	
+	// Analizar el resto de los hijos (bloque then, etc.)
+	for _, child := range []parser.Node{node.Test, node.Body} {
+		sa.analyzeNode(child)
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeCondition(node parser.Expr) {
+	if binary, isBinary := node.(*parser.BinaryOp); isBinary {
+		sa.analyzeBinaryOperation(binary)
+	} else {
+		sa.analyzeNode(node)
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeBinaryOperation(node *parser.BinaryOp) {
+	sa.checkOperands(node.Line, node.Op, sa.inferType(node.Left), sa.inferType(node.Right))
 	
-	leftType := sa.inferType(leftNode)
-	rightType := sa.inferType(rightNode)
+	// Analizar recursivamente los nodos hijos
+	sa.analyzeNode(node.Left)
+	sa.analyzeNode(node.Right)
+}
+
+// analyzeComparisonChain analiza a < b <= c comparando cada par de
+// operandos consecutivos.
+func (sa *SemanticAnalyzer) analyzeComparisonChain(node *parser.Compare) {
+	operands := append([]parser.Expr{node.Left}, node.Comparators...)
+	for i, operator := range node.Ops {
+		sa.checkOperands(node.Line, operator,
+			sa.inferType(operands[i]), sa.inferType(operands[i+1]))
+	}
 	
+	sa.analyzeExprs(operands)
+}
+
+func (sa *SemanticAnalyzer) checkOperands(line int, operator string, leftType, rightType VarType) {
 	// Verificar compatibilidad de tipos según el operador
 	switch operator {
 	case ">", "<", ">=", "<=":
 		// Operadores de comparación numérica
-		if leftType == StringType && rightType == IntType {
-			sa.addError(node.Line, 
+		if leftType == StringType && isNumber(rightType) {
+			sa.addError(line, 
 				fmt.Sprintf("No se puede comparar string con número usando '%s'", operator))
-		} else if leftType == IntType && rightType == StringType {
-			sa.addError(node.Line, 
+		} else if isNumber(leftType) && rightType == StringType {
+			sa.addError(line, 
 				fmt.Sprintf("No se puede comparar número con string usando '%s'", operator))
 		}
 		
 	case "==", "!=":
 		// Operadores de igualdad (más permisivos pero aún verificamos algunos casos)
-		if leftType == StringType && rightType == IntType {
-			sa.addError(node.Line, 
+		if leftType == StringType && isNumber(rightType) {
+			sa.addError(line, 
 				fmt.Sprintf("Comparación entre tipos incompatibles: string y número"))
-		} else if leftType == IntType && rightType == StringType {
-			sa.addError(node.Line, 
+		} else if isNumber(leftType) && rightType == StringType {
+			sa.addError(line, 
 				fmt.Sprintf("Comparación entre tipos incompatibles: número y string"))
 		}
 		
-	case "+", "-", "*", "/":
+	case "in", "not in":
+		if isNumber(rightType) || rightType == BoolType || rightType == NoneType {
+			sa.addError(line,
+				fmt.Sprintf("El operando derecho de '%s' debe ser una colección o string, no %s", operator, rightType))
+		}
+		
+	case "+":
+		// Con tipos conocidos, también los de una anotación, '+' no
+		// concatena un número con un string
+		if numberAndString(leftType, rightType) {
+			sa.addError(line,
+				fmt.Sprintf("Tipos incompatibles para '+': %s y %s", leftType, rightType))
+		}
+		
+	case "*":
+		// "ab" * 3 repite el string
+		if leftType == StringType && (rightType == StringType || rightType == ListType) ||
+			rightType == StringType && leftType == ListType {
+			sa.addError(line, "Operador '*' no válido para strings")
+		}
+		
+	case "%":
+		// "%d" % n es formato de strings
+		if rightType == StringType && leftType != StringType && leftType != UnknownType {
+			sa.addError(line, "Operador '%' no válido para strings")
+		}
+		
+	case "-", "/", "//", "**":
 		// Operadores aritméticos
 		if leftType == StringType || rightType == StringType {
-			if operator != "+" { // + puede ser concatenación
-				sa.addError(node.Line, 
-					fmt.Sprintf("Operador '%s' no válido para strings", operator))
-			}
+			sa.addError(line, 
+				fmt.Sprintf("Operador '%s' no válido para strings", operator))
 		}
 	}
+}
+
+// isNumber indica si el tipo es int o float.
+func isNumber(t VarType) bool {
+	return t == IntType || t == FloatType
+}
+
+// numberAndString indica si uno de los tipos es int, float o bool y el otro
+// string.
+func numberAndString(left, right VarType) bool {
+	scalar := func(t VarType) bool { return isNumber(t) || t == BoolType }
+	return scalar(left) && right == StringType || left == StringType && scalar(right)
+}
+
+func (sa *SemanticAnalyzer) analyzeLambda(node *parser.Lambda) {
+	// Los valores por defecto se evalúan fuera de la lambda
+	for _, param := range node.Params {
+		sa.analyzeNode(param.Default)
+	}
 	
-	// Analizar recursivamente los nodos hijos
-	sa.analyzeNode(leftNode)
-	sa.analyzeNode(rightNode)
+	sa.pushScope("lambda")
+	for _, param := range node.Params {
+		sa.declare(newVariable(param.Name, parameterType(param), param.Line))
+	}
+	sa.analyzeNode(node.Body)
+	sa.popScope()
 }
 
-func (sa *SemanticAnalyzer) analyzeFunctionCall(node *parser.ASTNode) {
-	// Verificar llamadas a funciones conocidas
-	funcName := node.Value
+// analyzeNamedExpr liga el destino de ":=". Dentro de una comprensión el
+// nombre pertenece a la función o módulo que la contiene.
+func (sa *SemanticAnalyzer) analyzeNamedExpr(node *parser.NamedExpr) {
+	target := node.Target.Name
+	value := node.Value
 	
-	if strings.Contains(funcName, ".") {
-		// Es una llamada a método
-		parts := strings.Split(funcName, ".")
-		if len(parts) == 2 {
-			objectName := parts[0]
-			methodName := parts[1]
-			
-			// Verificar si el objeto está definido
-			if variable, exists := sa.variables[objectName]; exists {
-				// Verificar métodos específicos según el tipo
-				if variable.Type == StringType && methodName == "lower" {
-					// Método válido para strings
-				} else if variable.Type != StringType && methodName == "lower" {
-					sa.addError(node.Line, 
-						fmt.Sprintf("El método 'lower()' no está disponible para el tipo de '%s'", objectName))
-				}
-			} else {
-				sa.addError(node.Line, 
-					fmt.Sprintf("Variable '%s' no está definida", objectName))
-			}
+	sa.analyzeNode(value)
+	
+	variable := newVariable(target, sa.inferType(value), node.Line)
+	variable.ElementType, variable.KeyType = sa.inferElementTypes(value)
+	for i := len(sa.scopes) - 1; i >= 0; i-- {
+		if sa.scopes[i].kind != "comprehension" {
+			sa.scopes[i].variables[target] = variable
+			return
 		}
-	} else if funcName == "print" {
-		// Verificar argumentos de print
-		for _, arg := range node.Children {
-			sa.analyzeNode(arg)
+		if _, exists := sa.scopes[i].variables[target]; exists {
+			sa.addError(node.Line,
+				fmt.Sprintf("':=' no puede reasignar '%s', la variable de iteración de la comprensión", target))
+			return
 		}
 	}
-	
-	// Analizar argumentos
-	for _, child := range node.Children {
-		sa.analyzeNode(child)
-	}
 }
 
-func (sa *SemanticAnalyzer) inferType(node *parser.ASTNode) VarType {
-	if node == nil {
-		return UnknownType
+// analyzeComprehension analiza una comprensión en su propio ámbito para que
+// las variables de iteración no se filtren fuera de ella. Solo el primer
+// iterable se evalúa en el ámbito que la contiene.
+func (sa *SemanticAnalyzer) analyzeComprehension(node parser.Node) {
+	var elements []parser.Expr
+	var clauses []*parser.Comprehension
+	switch n := node.(type) {
+	case *parser.ListComp:
+		elements, clauses = []parser.Expr{n.Elt}, n.Generators
+	case *parser.SetComp:
+		elements, clauses = []parser.Expr{n.Elt}, n.Generators
+	case *parser.GeneratorExp:
+		elements, clauses = []parser.Expr{n.Elt}, n.Generators
+	case *parser.DictComp:
+		elements, clauses = []parser.Expr{n.Key, n.Value}, n.Generators
 	}
 	
-	switch node.Type {
-	case "Number":
-		return IntType
-	case "String":
-		return StringType
-	case "Identifier":
-		if variable, exists := sa.variables[node.Value]; exists {
-			return variable.Type
-		}
-		return UnknownType
-	case "BinaryOp":
-		// El tipo depende del operador y operandos
-		operator := node.Value
-		if operator == ">" || operator == "<" || operator == ">=" || 
-		   operator == "<=" || operator == "==" || operator == "!=" {
-			return BoolType
-		}
-		// Para operadores aritméticos, inferir del contexto
-		if len(node.Children) >= 2 {
-			leftType := sa.inferType(node.Children[0])
-			rightType := sa.inferType(node.Children[1])
-			if leftType == IntType && rightType == IntType {
-				return IntType
-			}
-			if leftType == StringType || rightType == StringType {
-				return StringType
+	sa.analyzeNode(clauses[0].Iter)
+	
+	// Un generador async puede crearse en cualquier parte, pero las demás
+	// comprensiones async solo dentro de una función async
+	if _, isGenerator := node.(*parser.GeneratorExp); !isGenerator {
+		for _, clause := range clauses {
+			if clause.Async {
+				sa.checkAsyncContext(clause.Line, "'async for' en una comprensión")
+				break
 			}
 		}
-		return UnknownType
-	case "MethodCall":
-		// Inferir tipo basado en el método
-		if strings.Contains(node.Value, ".lower") {
-			return StringType
+	}
+	
+	sa.pushScope("comprehension")
+	for i, clause := range clauses {
+		if i > 0 {
+			sa.analyzeNode(clause.Iter)
 		}
-		return UnknownType
-	case "FunctionCall":
-		// print no retorna valor útil para comparaciones
-		return UnknownType
-	default:
-		return UnknownType
+		sa.bindIterationTarget(clause.Target, clause.Iter, clause.Line)
+		
+		sa.analyzeExprs(clause.Ifs)
 	}
+	sa.analyzeExprs(elements)
+	sa.popScope()
 }
 
-func (sa *SemanticAnalyzer) addError(line int, message string) {
-	sa.errors = append(sa.errors, fmt.Sprintf("Error semántico en línea %d: %s", line, message))
+// bindIterationTarget liga el destino de un for con el tipo de los elementos
+// del iterable.
+func (sa *SemanticAnalyzer) bindIterationTarget(target, iterable parser.Expr, line int) {
+	elementType := UnknownType
+	
+	switch iterableType := sa.inferType(iterable); iterableType {
+	case IntType, BoolType, FloatType, NoneType:
+		sa.addError(line,
+			fmt.Sprintf("El tipo '%s' no es iterable", iterableType))
+	case StringType:
+		elementType = StringType
+	case DictType:
+		// Recorrer un diccionario devuelve sus claves
+		_, elementType = sa.inferElementTypes(iterable)
+	case ListType, TupleType, SetType:
+		elementType, _ = sa.inferElementTypes(iterable)
+	}
+	
+	sa.bindTarget(target, elementType, line)
+}
+
+func (sa *SemanticAnalyzer) analyzeFunctionCall(node *parser.Call) {
+	switch callee := node.Func.(type) {
+	case *parser.Attribute:
+		// Llamada a método: obj.metodo(...)
+		sa.checkAttribute(callee, true)
+		sa.analyzeNode(callee.Value)
+		
+	case *parser.Identifier:
+		if callee.Name == "open" {
+			sa.addWarning(node.Line,
+				"open() fuera de una sentencia 'with': el archivo podría quedar abierto si ocurre una excepción")
+		}
+		if function, exists := sa.functions[callee.Name]; exists {
+			sa.checkCallArguments(node, function)
+		}
+		sa.analyzeNode(callee)
+		
+	default:
+		sa.analyzeNode(callee)
+	}
+	
+	// Analizar argumentos (incluidos los de print)
+	sa.analyzeArguments(node)
+}
+
+func (sa *SemanticAnalyzer) analyzeArguments(call *parser.Call) {
+	sa.analyzeExprs(call.Args)
+	for _, keyword := range call.Keywords {
+		sa.analyzeNode(keyword.Value)
+	}
+}
+
+// checkCallArguments compara los argumentos de una llamada con los
+// parámetros de la función definida, como lo hace Python al llamarla.
+func (sa *SemanticAnalyzer) checkCallArguments(call *parser.Call, function *parser.FunctionDef) {
+	name := function.Name
+	
+	positional := 0
+	unpacked := false
+	for _, arg := range call.Args {
+		if _, isStarred := arg.(*parser.Starred); isStarred {
+			unpacked = true
+		} else {
+			positional++
+		}
+	}
+	// Un nombre repetido, como en f(b=2, b=3), ya lo rechaza el parser
+	keywords := []*parser.Keyword{}
+	named := map[string]bool{}
+	for _, keyword := range call.Keywords {
+		switch {
+		case keyword.Name == "":
+			unpacked = true
+		case !named[keyword.Name]:
+			named[keyword.Name] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+	
+	positionalParams := []*parser.Parameter{}
+	byName := map[string]*parser.Parameter{}
+	hasVarArgs := false
+	hasKwArgs := false
+	for _, param := range function.Params {
+		switch param.Kind {
+		case parser.PositionalOnlyParam, parser.RegularParam:
+			positionalParams = append(positionalParams, param)
+			byName[param.Name] = param
+		case parser.KeywordOnlyParam:
+			byName[param.Name] = param
+		case parser.VarArgsParam:
+			hasVarArgs = true
+		case parser.KwArgsParam:
+			hasKwArgs = true
+		}
+	}
+	
+	if !hasVarArgs && positional > len(positionalParams) {
+		sa.addError(call.Line,
+			fmt.Sprintf("'%s' recibe como máximo %d argumentos posicionales pero se pasaron %d", name, len(positionalParams), positional))
+	}
+	
+	filled := map[string]bool{}
+	for i := 0; i < positional && i < len(positionalParams); i++ {
+		filled[positionalParams[i].Name] = true
+	}
+	
+	for _, keyword := range keywords {
+		param, exists := byName[keyword.Name]
+		if exists && param.Kind == parser.PositionalOnlyParam {
+			exists = false
+			if !hasKwArgs {
+				sa.addError(call.Line,
+					fmt.Sprintf("'%s' es un parámetro solo posicional de '%s' y no puede pasarse por nombre", keyword.Name, name))
+				continue
+			}
+		}
+		if !exists {
+			if !hasKwArgs {
+				sa.addError(call.Line,
+					fmt.Sprintf("'%s' no tiene un parámetro llamado '%s'", name, keyword.Name))
+			}
+			continue
+		}
+		if filled[keyword.Name] {
+			sa.addError(call.Line,
+				fmt.Sprintf("'%s' recibió varios valores para el argumento '%s'", name, keyword.Name))
+		}
+		filled[keyword.Name] = true
+	}
+	
+	// Con *args o **kwargs en la llamada no se sabe qué parámetros se cubren
+	if unpacked {
+		return
+	}
+	for _, param := range function.Params {
+		switch param.Kind {
+		case parser.PositionalOnlyParam, parser.RegularParam, parser.KeywordOnlyParam:
+			if param.Default == nil && !filled[param.Name] {
+				sa.addError(call.Line,
+					fmt.Sprintf("Falta el argumento obligatorio '%s' en la llamada a '%s'", param.Name, name))
+			}
+		}
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeAttribute(node *parser.Attribute) {
+	sa.checkAttribute(node, false)
+	sa.analyzeNode(node.Value)
+}
+
+// checkAttribute verifica que el objeto esté definido y que el atributo o
+// método exista para su tipo, cuando el tipo es conocido.
+func (sa *SemanticAnalyzer) checkAttribute(node *parser.Attribute, isCall bool) {
+	object := node.Value
+	
+	if name, isName := object.(*parser.Identifier); isName {
+		_, exists := sa.lookup(name.Name)
+		_, deleted := sa.handlerNames[name.Name]
+		if !exists && !deleted {
+			sa.addError(node.Line,
+				fmt.Sprintf("Variable '%s' no está definida", name.Name))
+			return
+		}
+	}
+	
+	objectType := sa.inferType(object)
+	attributes, known := typeAttributes[objectType]
+	if !known {
+		return
+	}
+	if _, exists := attributes[node.Attr]; exists {
+		return
+	}
+	
+	if isCall {
+		sa.addError(node.Line,
+			fmt.Sprintf("El método '%s()' no está disponible para el tipo '%s'", node.Attr, objectType))
+	} else {
+		sa.addError(node.Line,
+			fmt.Sprintf("El atributo '%s' no está disponible para el tipo '%s'", node.Attr, objectType))
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeTry(node *parser.Try) {
+	sa.analyzeNode(node.Body)
+	
+	// Excepciones ya capturadas por manejadores anteriores
+	caught := []string{}
+	
+	for _, handler := range node.Handlers {
+		if handler.Type == nil {
+			sa.addWarning(handler.Line,
+				"'except:' sin tipo captura todas las excepciones, incluidas SystemExit y KeyboardInterrupt; use 'except Exception:'")
+		} else {
+			names := exceptionNames(handler.Type)
+			for _, name := range names {
+				for _, previous := range caught {
+					if isSubclassOf(name, previous) {
+						sa.addWarning(handler.Line,
+							fmt.Sprintf("El manejador 'except %s' es inalcanzable: '%s' ya fue capturada por 'except %s'", name, name, previous))
+						break
+					}
+				}
+			}
+			caught = append(caught, names...)
+		}
+		
+		sa.analyzeExceptHandler(handler)
+	}
+	
+	sa.analyzeClause(node.Else)
+	sa.analyzeClause(node.Finally)
+}
+
+func (sa *SemanticAnalyzer) analyzeExceptHandler(node *parser.ExceptHandler) {
+	sa.analyzeNode(node.Type)
+	
+	// El nombre de "except E as e" solo existe dentro del manejador
+	name := node.Name
+	if name != "" {
+		delete(sa.handlerNames, name)
+		sa.declare(newVariable(name, UnknownType, node.Line))
+	}
+	
+	sa.handlerDepth++
+	sa.analyzeNode(node.Body)
+	sa.handlerDepth--
+	
+	if name != "" {
+		delete(sa.currentScope().variables, name)
+		sa.handlerNames[name] = node.Line
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeWith(node *parser.With) {
+	if node.Async {
+		sa.checkAsyncContext(node.Line, "'async with'")
+	}
+	
+	for _, item := range node.Items {
+		if isCallTo(item.Context, "open") {
+			// open() dentro de with se cierra automáticamente
+			sa.analyzeArguments(item.Context.(*parser.Call))
+		} else {
+			sa.analyzeNode(item.Context)
+		}
+		
+		if item.Target != nil {
+			sa.bindTarget(item.Target, UnknownType, item.Line)
+		}
+	}
+	
+	sa.analyzeNode(node.Body)
+}
+
+// analyzeMatch analiza el sujeto y cada case. Un patrón irrefutable sin
+// guarda, como una captura o el comodín _, hace inalcanzables los case
+// siguientes.
+func (sa *SemanticAnalyzer) analyzeMatch(node *parser.Match) {
+	subject := node.Subject
+	sa.analyzeNode(subject)
+	
+	cases := node.Cases
+	for i, matchCase := range cases {
+		pattern := matchCase.Pattern
+		guarded := matchCase.Guard != nil
+		
+		if !guarded && i < len(cases)-1 {
+			if description, irrefutable := irrefutablePattern(pattern); irrefutable {
+				sa.addError(matchCase.Line,
+					fmt.Sprintf("%s hace inalcanzables los case siguientes", description))
+			}
+		}
+		
+		names := map[string]bool{}
+		sa.checkPattern(pattern, names)
+		
+		// "case x:" liga el sujeto completo
+		if capture, isCapture := pattern.(*parser.MatchAs); isCapture && capture.Pattern == nil && capture.Name != "" {
+			sa.declare(sa.valueVariable(capture.Name, subject, matchCase.Line))
+		} else {
+			for name := range names {
+				sa.declare(newVariable(name, UnknownType, matchCase.Line))
+			}
+		}
+		
+		sa.analyzeNode(matchCase.Guard)
+		sa.analyzeNode(matchCase.Body)
+	}
+}
+
+// irrefutablePattern indica si un patrón coincide con cualquier valor y
+// devuelve una descripción para el mensaje de error.
+func irrefutablePattern(pattern parser.Pattern) (string, bool) {
+	switch p := pattern.(type) {
+	case *parser.MatchAs:
+		if p.Pattern != nil {
+			return irrefutablePattern(p.Pattern)
+		}
+		if p.Name == "" {
+			return "El comodín '_'", true
+		}
+		return fmt.Sprintf("La captura '%s'", p.Name), true
+	case *parser.MatchOr:
+		for _, alternative := range p.Patterns {
+			if description, irrefutable := irrefutablePattern(alternative); irrefutable {
+				return description, true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkPattern recorre un patrón, analiza los valores que contiene y junta
+// en names los nombres que captura.
+func (sa *SemanticAnalyzer) checkPattern(pattern parser.Pattern, names map[string]bool) {
+	capture := func(name string) {
+		if name == "" {
+			return
+		}
+		if names[name] {
+			sa.addError(pattern.Pos().Line,
+				fmt.Sprintf("El nombre '%s' se captura más de una vez en el patrón", name))
+		}
+		names[name] = true
+	}
+	
+	switch p := pattern.(type) {
+	case *parser.MatchAs:
+		if p.Pattern != nil {
+			sa.checkPattern(p.Pattern, names)
+		}
+		capture(p.Name)
+		
+	case *parser.MatchStar:
+		capture(p.Name)
+		
+	case *parser.MatchMapping:
+		for i, key := range p.Keys {
+			sa.checkPattern(key, names)
+			sa.checkPattern(p.Patterns[i], names)
+		}
+		capture(p.Rest)
+		
+	case *parser.MatchValue:
+		sa.analyzeNode(p.Value)
+		
+	case *parser.MatchOr:
+		// Todas las alternativas deben capturar los mismos nombres
+		var first map[string]bool
+		for i, alternative := range p.Patterns {
+			if i < len(p.Patterns)-1 {
+				if description, irrefutable := irrefutablePattern(alternative); irrefutable {
+					sa.addError(alternative.Pos().Line,
+						fmt.Sprintf("%s hace inalcanzables las alternativas siguientes", description))
+				}
+			}
+			
+			alternativeNames := map[string]bool{}
+			sa.checkPattern(alternative, alternativeNames)
+			if first == nil {
+				first = alternativeNames
+			} else if !sameNames(first, alternativeNames) {
+				sa.addError(p.Line, "Las alternativas de un patrón '|' deben capturar los mismos nombres")
+			}
+		}
+		for name := range first {
+			capture(name)
+		}
+		
+	case *parser.MatchSequence:
+		for _, child := range p.Patterns {
+			sa.checkPattern(child, names)
+		}
+		
+	case *parser.MatchClass:
+		// La clase de un patrón de clase
+		sa.analyzeNode(p.Cls)
+		for _, child := range p.Patterns {
+			sa.checkPattern(child, names)
+		}
+		for _, keyword := range p.Keywords {
+			sa.checkPattern(keyword.Pattern, names)
+		}
+	}
+}
+
+func sameNames(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if !b[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeNameDeclaration aplica "global" y "nonlocal": la declaración debe
+// preceder a cualquier uso del nombre en el ámbito, y nonlocal debe referirse
+// a una variable de una función que contiene a la actual.
+func (sa *SemanticAnalyzer) analyzeNameDeclaration(line int, keyword string, names []*parser.Identifier) {
+	current := sa.currentScope()
+	
+	for _, name := range names {
+		previous, declared := current.declarations[name.Name]
+		switch {
+		case declared && previous != keyword:
+			sa.addError(line,
+				fmt.Sprintf("'%s' no puede declararse %s y %s a la vez", name.Name, previous, keyword))
+			continue
+		case current.used[name.Name] && !declared:
+			sa.addError(line,
+				fmt.Sprintf("'%s' se usa antes de su declaración %s", name.Name, keyword))
+		}
+		
+		if keyword == "nonlocal" {
+			if current.kind == "module" || current.kind == "class" && len(sa.scopes) == 2 {
+				sa.addError(line, "'nonlocal' no está permitido fuera de una función")
+				return
+			}
+			if sa.enclosingBinding(name.Name) == nil {
+				sa.addError(line,
+					fmt.Sprintf("No existe una variable '%s' en una función externa para 'nonlocal'", name.Name))
+				continue
+			}
+		}
+		current.declarations[name.Name] = keyword
+	}
+}
+
+// deleteTarget aplica "del" a un destino. Eliminar un nombre lo quita del
+// ámbito donde está ligado.
+func (sa *SemanticAnalyzer) deleteTarget(target parser.Expr) {
+	switch t := target.(type) {
+	case *parser.Identifier:
+		sa.currentScope().used[t.Name] = true
+		bindingScope := sa.bindingScope(t.Name)
+		if _, exists := bindingScope.variables[t.Name]; !exists {
+			sa.addError(t.Line,
+				fmt.Sprintf("No se puede eliminar '%s': la variable no está definida", t.Name))
+			return
+		}
+		delete(bindingScope.variables, t.Name)
+		
+	case *parser.Tuple, *parser.List:
+		elements, _ := sequenceElements(target)
+		for _, element := range elements {
+			sa.deleteTarget(element)
+		}
+		
+	case *parser.Subscript:
+		switch targetType := sa.inferType(t.Value); targetType {
+		case StringType, TupleType:
+			sa.addError(t.Line,
+				fmt.Sprintf("El tipo '%s' no admite eliminación por índice", targetType))
+		}
+		sa.analyzeNode(target)
+		
+	default:
+		sa.analyzeNode(target)
+	}
+}
+
+// analyzeAssert advierte sobre "assert (condición, mensaje)": una tupla no
+// vacía siempre es verdadera, así que la aserción nunca falla.
+func (sa *SemanticAnalyzer) analyzeAssert(node *parser.Assert) {
+	if tuple, isTuple := node.Test.(*parser.Tuple); isTuple && len(tuple.Elts) > 0 {
+		sa.addWarning(node.Line,
+			"La aserción siempre es verdadera porque la condición es una tupla no vacía; quite los paréntesis")
+	}
+	
+	sa.analyzeNode(node.Test)
+	sa.analyzeNode(node.Msg)
+}
+
+// boundNames junta los nombres que liga un bloque, sin entrar en las
+// funciones, clases, lambdas ni comprensiones que contiene.
+func boundNames(node parser.Node, names map[string]bool) {
+	parser.Inspect(node, func(child parser.Node) bool {
+		switch n := child.(type) {
+		case *parser.FunctionDef:
+			names[n.Name] = true
+			return false
+		case *parser.ClassDef:
+			names[n.Name] = true
+			return false
+		case *parser.Lambda, *parser.ListComp, *parser.SetComp, *parser.DictComp, *parser.GeneratorExp:
+			return false
+		case *parser.Assign:
+			for _, target := range n.Targets {
+				targetNames(target, names)
+			}
+		case *parser.AugAssign:
+			targetNames(n.Target, names)
+		case *parser.AnnAssign:
+			targetNames(n.Target, names)
+		case *parser.For:
+			targetNames(n.Target, names)
+		case *parser.NamedExpr:
+			names[n.Target.Name] = true
+		case *parser.WithItem:
+			if n.Target != nil {
+				targetNames(n.Target, names)
+			}
+		case *parser.ExceptHandler:
+			addName(names, n.Name)
+		case *parser.MatchAs:
+			addName(names, n.Name)
+		case *parser.MatchStar:
+			addName(names, n.Name)
+		case *parser.MatchMapping:
+			addName(names, n.Rest)
+		}
+		return true
+	})
+}
+
+func addName(names map[string]bool, name string) {
+	if name != "" {
+		names[name] = true
+	}
+}
+
+func targetNames(target parser.Expr, names map[string]bool) {
+	switch t := target.(type) {
+	case *parser.Identifier:
+		names[t.Name] = true
+	case *parser.Tuple, *parser.List:
+		elements, _ := sequenceElements(target)
+		for _, element := range elements {
+			targetNames(element, names)
+		}
+	case *parser.Starred:
+		targetNames(t.Value, names)
+	}
+}
+
+// bindTarget registra los nombres ligados por un destino (as, for, etc.).
+func (sa *SemanticAnalyzer) bindTarget(target parser.Expr, varType VarType, line int) {
+	switch t := target.(type) {
+	case *parser.Identifier:
+		sa.declare(newVariable(t.Name, varType, line))
+	case *parser.Tuple, *parser.List:
+		elements, _ := sequenceElements(target)
+		for _, element := range elements {
+			if starred, isStarred := element.(*parser.Starred); isStarred {
+				sa.bindTarget(starred.Value, ListType, line)
+			} else {
+				sa.bindTarget(element, UnknownType, line)
+			}
+		}
+	default:
+		sa.analyzeNode(target)
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeRaise(node *parser.Raise) {
+	if node.Exc == nil {
+		if sa.handlerDepth == 0 {
+			sa.addWarning(node.Line, "'raise' sin excepción fuera de un bloque except no tiene excepción activa que relanzar")
+		}
+		return
+	}
+	
+	raised := []parser.Expr{node.Exc}
+	// "raise E from None" suprime el contexto y no se verifica
+	if _, isNone := node.Cause.(*parser.None); node.Cause != nil && !isNone {
+		raised = append(raised, node.Cause)
+	}
+	for _, child := range raised {
+		switch sa.inferType(child) {
+		case IntType, StringType, BoolType:
+			sa.addError(node.Line, "Solo se pueden lanzar instancias o subclases de BaseException")
+		}
+		sa.analyzeNode(child)
+	}
+}
+
+// checkHandlerName reporta el uso del nombre ligado por "except ... as nombre"
+// después de terminar su manejador, cuando Python ya lo eliminó.
+func (sa *SemanticAnalyzer) checkHandlerName(node *parser.Identifier) {
+	line, exists := sa.handlerNames[node.Name]
+	if !exists {
+		return
+	}
+	if _, defined := sa.lookup(node.Name); defined {
+		return
+	}
+	sa.addError(node.Line,
+		fmt.Sprintf("'%s' solo existe dentro del manejador except de la línea %d", node.Name, line))
+}
+
+func exceptionNames(node parser.Expr) []string {
+	switch n := node.(type) {
+	case *parser.Identifier:
+		return []string{n.Name}
+	case *parser.Tuple:
+		// except (ValueError, TypeError):
+		names := []string{}
+		for _, child := range n.Elts {
+			names = append(names, exceptionNames(child)...)
+		}
+		return names
+	}
+	return nil
+}
+
+func isSubclassOf(name, parent string) bool {
+	for current := name; current != ""; {
+		if current == parent {
+			return true
+		}
+		base, known := builtinExceptions[current]
+		if !known {
+			return false
+		}
+		current = base
+	}
+	return false
+}
+
+// analyzeHashable verifica que las claves de un dict y los elementos de un
+// set no sean colecciones mutables.
+func (sa *SemanticAnalyzer) analyzeHashable(node parser.Node) {
+	var keys, values []parser.Expr
+	switch n := node.(type) {
+	case *parser.Dict:
+		keys, values = n.Keys, n.Values
+	case *parser.Set:
+		keys = n.Elts
+	}
+	_, isDict := node.(*parser.Dict)
+	
+	for i, key := range keys {
+		if key == nil {
+			// **otro dentro de un dict
+			sa.analyzeNode(values[i])
+			continue
+		}
+		
+		switch keyType := sa.inferType(key); keyType {
+		case ListType, DictType, SetType:
+			if isDict {
+				sa.addError(key.Pos().Line,
+					fmt.Sprintf("El tipo '%s' no es hashable y no puede usarse como clave de diccionario", keyType))
+			} else {
+				sa.addError(key.Pos().Line,
+					fmt.Sprintf("El tipo '%s' no es hashable y no puede ser elemento de un set", keyType))
+			}
+		}
+		sa.analyzeNode(key)
+		if isDict {
+			sa.analyzeNode(values[i])
+		}
+	}
+}
+
+func (sa *SemanticAnalyzer) analyzeSubscript(node *parser.Subscript) {
+	value := node.Value
+	index := node.Index
+	slice, isSlice := index.(*parser.Slice)
+	
+	switch valueType := sa.inferType(value); valueType {
+	case IntType, BoolType, SetType, FloatType, NoneType:
+		sa.addError(node.Line,
+			fmt.Sprintf("El tipo '%s' no admite índices", valueType))
+			
+	case ListType, TupleType, StringType:
+		if !isSlice {
+			switch indexType := sa.inferType(index); indexType {
+			case StringType, ListType, TupleType, DictType, SetType, FloatType, NoneType:
+				sa.addError(node.Line,
+					fmt.Sprintf("Los índices de '%s' deben ser enteros, no %s", valueType, indexType))
+			}
+		}
+		
+	case DictType:
+		if isSlice {
+			sa.addError(node.Line, "El tipo 'dict' no admite rebanadas")
+		}
+	}
+	
+	if isSlice {
+		for _, part := range []parser.Expr{slice.Lower, slice.Upper, slice.Step} {
+			if sa.inferType(part) == StringType {
+				sa.addError(part.Pos().Line, "Los límites de una rebanada deben ser enteros")
+			}
+		}
+	}
+	
+	sa.analyzeNode(value)
+	sa.analyzeNode(index)
+}
+
+// inferSubscriptType deduce el tipo de valor[índice] a partir del tipo de
+// los elementos de la colección.
+func (sa *SemanticAnalyzer) inferSubscriptType(node *parser.Subscript) VarType {
+	value := node.Value
+	valueType := sa.inferType(value)
+	
+	if _, isSlice := node.Index.(*parser.Slice); isSlice {
+		switch valueType {
+		case ListType, TupleType, StringType:
+			return valueType
+		}
+		return UnknownType
+	}
+	
+	switch valueType {
+	case StringType:
+		return StringType
+	case ListType, TupleType, DictType:
+		element, _ := sa.inferElementTypes(value)
+		return element
+	}
+	return UnknownType
+}
+
+// inferElementTypes devuelve el tipo de los elementos y de las claves de una
+// colección. Si los elementos tienen tipos distintos el resultado es
+// UnknownType.
+func (sa *SemanticAnalyzer) inferElementTypes(node parser.Expr) (VarType, VarType) {
+	switch n := node.(type) {
+	case *parser.List:
+		return sa.elementType(n.Elts), UnknownType
+	case *parser.Tuple:
+		return sa.elementType(n.Elts), UnknownType
+	case *parser.Set:
+		return sa.elementType(n.Elts), UnknownType
+		
+	case *parser.Dict:
+		keys := []VarType{}
+		values := []VarType{}
+		for i, key := range n.Keys {
+			if key != nil {
+				keys = append(keys, sa.inferType(key))
+				values = append(values, sa.inferType(n.Values[i]))
+			} else {
+				valueType, keyType := sa.inferElementTypes(n.Values[i])
+				keys = append(keys, keyType)
+				values = append(values, valueType)
+			}
+		}
+		return commonType(values), commonType(keys)
+		
+	case *parser.Identifier:
+		if variable, exists := sa.lookup(n.Name); exists {
+			return variable.ElementType, variable.KeyType
+		}
+	}
+	
+	return UnknownType, UnknownType
+}
+
+// elementType devuelve el tipo común de los elementos de una lista, tupla o
+// set literal. Un *iterable aporta el tipo de sus propios elementos.
+func (sa *SemanticAnalyzer) elementType(elements []parser.Expr) VarType {
+	types := []VarType{}
+	for _, child := range elements {
+		if starred, isStarred := child.(*parser.Starred); isStarred {
+			element, _ := sa.inferElementTypes(starred.Value)
+			types = append(types, element)
+		} else {
+			types = append(types, sa.inferType(child))
+		}
+	}
+	return commonType(types)
+}
+
+func commonType(types []VarType) VarType {
+	if len(types) == 0 {
+		return UnknownType
+	}
+	for _, t := range types[1:] {
+		if t != types[0] {
+			return UnknownType
+		}
+	}
+	return types[0]
+}
+
+// parameterType devuelve el tipo de un parámetro dentro de la función:
+// *args es una tupla y **kwargs un diccionario.
+func parameterType(param *parser.Parameter) VarType {
+	switch param.Kind {
+	case parser.VarArgsParam:
+		return TupleType
+	case parser.KwArgsParam:
+		return DictType
+	}
+	return UnknownType
+}
+
+func isCallTo(node parser.Expr, name string) bool {
+	call, isCall := node.(*parser.Call)
+	if !isCall {
+		return false
+	}
+	callee, isName := call.Func.(*parser.Identifier)
+	return isName && callee.Name == name
+}
+
+func (sa *SemanticAnalyzer) currentScope() *scope {
+	return sa.scopes[len(sa.scopes)-1]
+}
+
+func (sa *SemanticAnalyzer) pushScope(kind string) {
+	sa.scopes = append(sa.scopes, newScope(kind, make(map[string]Variable)))
+}
+
+func newScope(kind string, variables map[string]Variable) *scope {
+	return &scope{
+		kind:         kind,
+		variables:    variables,
+		annotations:  make(map[string]declaredType),
+		declarations: make(map[string]string),
+		used:         make(map[string]bool),
+		bound:        make(map[string]bool),
+	}
+}
+
+func (sa *SemanticAnalyzer) popScope() {
+	sa.scopes = sa.scopes[:len(sa.scopes)-1]
+}
+
+// lookup busca un nombre desde el ámbito actual hacia el módulo. Como en
+// Python, los nombres de una clase no son visibles desde sus métodos.
+func (sa *SemanticAnalyzer) lookup(name string) (Variable, bool) {
+	for i := len(sa.scopes) - 1; i >= 0; i-- {
+		if sa.scopes[i].kind == "class" && i != len(sa.scopes)-1 {
+			continue
+		}
+		if variable, exists := sa.scopes[i].variables[name]; exists {
+			return variable, true
+		}
+	}
+	return Variable{}, false
+}
+
+// declare liga un nombre en el ámbito actual. Si el nombre tiene una
+// anotación, el valor debe ser compatible y la variable toma el tipo
+// declarado.
+func (sa *SemanticAnalyzer) declare(variable Variable) {
+	sa.currentScope().used[variable.Name] = true
+	current := sa.bindingScope(variable.Name)
+	if declared, exists := current.annotations[variable.Name]; exists {
+		if !declared.accepts(variable) {
+			sa.addError(variable.Line,
+				fmt.Sprintf("'%s' se declaró como %s pero recibe un valor de tipo %s", variable.Name, declared, describeType(variable)))
+		}
+		variable = declared.variable(variable.Name, variable.Line)
+	}
+	current.variables[variable.Name] = variable
+}
+
+// checkElements verifica uno por uno los elementos de una colección literal
+// que recibe un nombre anotado. En "x: list[int] = [1, 'a']" los elementos
+// no tienen un tipo común, así que declare no puede compararlo con el
+// declarado.
+func (sa *SemanticAnalyzer) checkElements(variable Variable, valueNode parser.Expr) {
+	declared, exists := sa.bindingScope(variable.Name).annotations[variable.Name]
+	if !exists || declared.Type != variable.Type || !declared.accepts(variable) {
+		return
+	}
+	mismatch := func(part string, actual VarType) {
+		sa.addError(variable.Line,
+			fmt.Sprintf("'%s' se declaró como %s pero %s es de tipo %s", variable.Name, declared, part, actual))
+	}
+	
+	var elements []parser.Expr
+	switch n := valueNode.(type) {
+	case *parser.List:
+		elements = n.Elts
+	case *parser.Tuple:
+		elements = n.Elts
+	case *parser.Set:
+		elements = n.Elts
+	case *parser.Dict:
+		for i, key := range n.Keys {
+			keyType, valueType := UnknownType, UnknownType
+			if key != nil {
+				keyType, valueType = sa.inferType(key), sa.inferType(n.Values[i])
+			} else {
+				valueType, keyType = sa.inferElementTypes(n.Values[i])
+			}
+			if !acceptsType(declared.KeyType, keyType) {
+				mismatch(fmt.Sprintf("la clave de la entrada %d", i+1), keyType)
+				return
+			}
+			if !acceptsType(declared.ElementType, valueType) {
+				mismatch(fmt.Sprintf("el valor de la entrada %d", i+1), valueType)
+				return
+			}
+		}
+	}
+	
+	for i, element := range elements {
+		elementType := sa.inferType(element)
+		if starred, isStarred := element.(*parser.Starred); isStarred {
+			elementType, _ = sa.inferElementTypes(starred.Value)
+		}
+		if !acceptsType(declared.ElementType, elementType) {
+			mismatch(fmt.Sprintf("el elemento %d", i+1), elementType)
+			return
+		}
+	}
+}
+
+// bindingScope devuelve el ámbito donde se liga un nombre, según las
+// declaraciones global y nonlocal del ámbito actual.
+func (sa *SemanticAnalyzer) bindingScope(name string) *scope {
+	switch sa.currentScope().declarations[name] {
+	case "global":
+		return sa.scopes[0]
+	case "nonlocal":
+		if enclosing := sa.enclosingBinding(name); enclosing != nil {
+			return enclosing
+		}
+	}
+	return sa.currentScope()
+}
+
+// enclosingBinding busca la función más cercana que contiene a la actual y
+// liga el nombre. Las clases y el módulo no cuentan para nonlocal.
+func (sa *SemanticAnalyzer) enclosingBinding(name string) *scope {
+	for i := len(sa.scopes) - 2; i > 0; i-- {
+		enclosing := sa.scopes[i]
+		if enclosing.kind != "function" && enclosing.kind != "lambda" {
+			continue
+		}
+		if enclosing.declarations[name] == "global" {
+			return nil
+		}
+		_, exists := enclosing.variables[name]
+		if exists || enclosing.bound[name] {
+			if enclosing.declarations[name] == "nonlocal" {
+				continue
+			}
+			return enclosing
+		}
+	}
+	return nil
+}
+
+// valueVariable crea la variable que resulta de ligar un nombre a un valor.
+func (sa *SemanticAnalyzer) valueVariable(name string, valueNode parser.Expr, line int) Variable {
+	variable := newVariable(name, sa.inferType(valueNode), line)
+	variable.ElementType, variable.KeyType = sa.inferElementTypes(valueNode)
+	return variable
+}
+
+func newVariable(name string, varType VarType, line int) Variable {
+	return Variable{
+		Name:        name,
+		Type:        varType,
+		Line:        line,
+		ElementType: UnknownType,
+		KeyType:     UnknownType,
+	}
+}
+
+func (sa *SemanticAnalyzer) inferType(node parser.Expr) VarType {
+	switch n := node.(type) {
+	case *parser.Number:
+		if strings.Contains(n.Value, ".") {
+			return FloatType
+		}
+		return IntType
+	case *parser.None:
+		return NoneType
+	case *parser.String:
+		return StringType
+	case *parser.Boolean:
+		return BoolType
+	case *parser.List:
+		return ListType
+	case *parser.Tuple:
+		return TupleType
+	case *parser.Dict:
+		return DictType
+	case *parser.Set:
+		return SetType
+	case *parser.ListComp:
+		return ListType
+	case *parser.SetComp:
+		return SetType
+	case *parser.DictComp:
+		return DictType
+	case *parser.Identifier:
+		if variable, exists := sa.lookup(n.Name); exists {
+			return variable.Type
+		}
+		return UnknownType
+	case *parser.Subscript:
+		return sa.inferSubscriptType(n)
+	case *parser.UnaryOp:
+		if n.Op == "not" {
+			return BoolType
+		}
+		if operandType := sa.inferType(n.Operand); isNumber(operandType) {
+			return operandType
+		}
+		return UnknownType
+	case *parser.Compare:
+		return BoolType
+	case *parser.BoolOp:
+		// 'and' y 'or' devuelven uno de sus operandos
+		types := []VarType{}
+		for _, value := range n.Values {
+			types = append(types, sa.inferType(value))
+		}
+		return commonType(types)
+	case *parser.IfExp:
+		return commonType([]VarType{sa.inferType(n.Body), sa.inferType(n.Orelse)})
+	case *parser.NamedExpr:
+		return sa.inferType(n.Value)
+	case *parser.BinaryOp:
+		// El tipo depende del operador y operandos
+		operator := n.Op
+		if operator == ">" || operator == "<" || operator == ">=" ||
+		   operator == "<=" || operator == "==" || operator == "!=" ||
+		   operator == "in" || operator == "not in" || operator == "is" || operator == "is not" {
+			return BoolType
+		}
+		// Para operadores aritméticos, inferir del contexto
+		leftType := sa.inferType(n.Left)
+		rightType := sa.inferType(n.Right)
+		if isNumber(leftType) && isNumber(rightType) {
+			if operator == "/" || leftType == FloatType || rightType == FloatType {
+				return FloatType
+			}
+			return IntType
+		}
+		// La suma de un número y un string ya se reportó en checkOperands
+		if operator == "+" && numberAndString(leftType, rightType) {
+			return UnknownType
+		}
+		if leftType == StringType || rightType == StringType {
+			return StringType
+		}
+		return UnknownType
+	case *parser.Call:
+		// Inferir tipo basado en el método
+		if callee, isAttribute := n.Func.(*parser.Attribute); isAttribute {
+			objectType := sa.inferType(callee.Value)
+			if returnType, exists := typeAttributes[objectType][callee.Attr]; exists {
+				return returnType
+			}
+		}
+		// Funciones con tipo de retorno anotado: def f() -> int
+		if callee, isName := n.Func.(*parser.Identifier); isName {
+			if function, exists := sa.functions[callee.Name]; exists {
+				if returns, annotated := sa.annotationType(function.Returns); annotated && !returns.Optional {
+					return returns.Type
+				}
+			}
+		}
+		// print no retorna valor útil para comparaciones
+		return UnknownType
+	default:
+		return UnknownType
+	}
+}
+
+func (sa *SemanticAnalyzer) addError(line int, message string) {
+	sa.errors = append(sa.errors, fmt.Sprintf("Error semántico en línea %d: %s", line, message))
+}
+
+func (sa *SemanticAnalyzer) addWarning(line int, message string) {
+	sa.warnings = append(sa.warnings, fmt.Sprintf("Advertencia en línea %d: %s", line, message))
 }
 
 func (sa *SemanticAnalyzer) getTypeMismatches() []string {
@@ -294,7 +2113,8 @@ func (sa *SemanticAnalyzer) getTypeMismatches() []string {
 	
 	// Buscar patrones específicos de incompatibilidad de tipos
 	for _, err := range sa.errors {
-		if strings.Contains(err, "comparar") || strings.Contains(err, "Comparación") {
+		if strings.Contains(err, "comparar") || strings.Contains(err, "Comparación") || strings.Contains(err, "se declaró como") ||
+			strings.Contains(err, "Tipos incompatibles") {
 			mismatches = append(mismatches, err)
 		}
 	}
@@ -310,6 +2130,18 @@ func (vt VarType) String() string {
 		return "string"
 	case BoolType:
 		return "bool"
+	case ListType:
+		return "list"
+	case TupleType:
+		return "tuple"
+	case DictType:
+		return "dict"
+	case SetType:
+		return "set"
+	case FloatType:
+		return "float"
+	case NoneType:
+		return "None"
 	default:
 		return "unknown"
 	}